@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/vimgym/vimgym/internal/progress"
+	"github.com/vimgym/vimgym/internal/puzzle"
+)
+
+// runCompare implements `vimgym compare <dirA> <dirB>`: a read-only,
+// head-to-head view of the best results in two data directories, with the
+// winner of each puzzle and the overall tally. It returns the exit code.
+func runCompare(args []string, all []puzzle.Puzzle, out io.Writer) int {
+	if len(args) != 2 {
+		fmt.Fprintln(out, "usage: vimgym compare <dirA> <dirB>")
+		return 2
+	}
+	stores := make([]*progress.Store, len(args))
+	for i, dir := range args {
+		s, err := progress.Open(dir)
+		if err != nil {
+			fmt.Fprintf(out, "Error: %s: %v\n", dir, err)
+			return 1
+		}
+		stores[i] = s
+	}
+	c := progress.Compare(stores[0], stores[1], all)
+	if len(c.Puzzles) == 0 {
+		fmt.Fprintln(out, "Neither side has solved a puzzle yet.")
+		return 0
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "PUZZLE\t%s\t\t%s\n", args[0], args[1])
+	for _, pc := range c.Puzzles {
+		mark := "="
+		switch pc.Winner {
+		case progress.WinnerA:
+			mark = "<"
+		case progress.WinnerB:
+			mark = ">"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", pc.Puzzle.ID, formatResult(pc.A), mark, formatResult(pc.B))
+	}
+	w.Flush()
+	fmt.Fprintf(out, "\nWins: %s %d, %s %d, ties %d\n", args[0], c.WinsA, args[1], c.WinsB, c.Ties)
+	return 0
+}
+
+// formatResult describes a best result as stars and keystrokes, or "-"
+// when the puzzle is unsolved.
+func formatResult(r progress.PuzzleResult) string {
+	if r.Stars == puzzle.NoStar {
+		return "-"
+	}
+	stars := int(r.Stars)
+	return fmt.Sprintf("%s%s %d keys", strings.Repeat("★", stars), strings.Repeat("☆", 3-stars), r.Keystrokes)
+}
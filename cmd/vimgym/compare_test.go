@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vimgym/vimgym/internal/progress"
+	"github.com/vimgym/vimgym/internal/puzzle"
+)
+
+// writeProgress saves a data directory with the given best results.
+func writeProgress(t *testing.T, bests map[string]puzzle.StarRating) string {
+	t.Helper()
+	dir := t.TempDir()
+	s, err := progress.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, stars := range bests {
+		s.SetBest(id, stars, 10-int(stars), nil)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestRunCompare(t *testing.T) {
+	all := []puzzle.Puzzle{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+	dirA := writeProgress(t, map[string]puzzle.StarRating{"a": puzzle.ThreeStar, "b": puzzle.OneStar, "c": puzzle.TwoStar})
+	dirB := writeProgress(t, map[string]puzzle.StarRating{"a": puzzle.TwoStar, "b": puzzle.OneStar})
+
+	var out bytes.Buffer
+	if code := runCompare([]string{dirA, dirB}, all, &out); code != 0 {
+		t.Fatalf("exit code %d\n%s", code, out.String())
+	}
+	lines := strings.Split(out.String(), "\n")
+	want := []string{
+		"a ★★★ 7 keys < ★★☆ 8 keys",
+		"b ★☆☆ 9 keys = ★☆☆ 9 keys",
+		"c ★★☆ 8 keys < -",
+	}
+	for i, w := range want {
+		if got := strings.Join(strings.Fields(lines[i+1]), " "); got != w {
+			t.Errorf("line %d = %q, want %q", i+1, got, w)
+		}
+	}
+	if strings.Contains(out.String(), "\nd ") {
+		t.Error("puzzle unsolved on both sides was listed")
+	}
+	if !strings.Contains(out.String(), "Wins: "+dirA+" 2, "+dirB+" 0, ties 1") {
+		t.Errorf("tally missing:\n%s", out.String())
+	}
+}
+
+func TestRunCompareErrors(t *testing.T) {
+	var out bytes.Buffer
+	if code := runCompare([]string{t.TempDir()}, nil, &out); code != 2 || !strings.Contains(out.String(), "usage:") {
+		t.Errorf("one argument: exit code %d, output %q; want 2 and the usage", code, out.String())
+	}
+
+	// A corrupt profile is reported and left where it is.
+	corrupt := t.TempDir()
+	path := filepath.Join(corrupt, "progress.json")
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if code := runCompare([]string{corrupt, t.TempDir()}, nil, &out); code != 1 || !strings.Contains(out.String(), "Error: "+corrupt) {
+		t.Errorf("corrupt profile: exit code %d, output %q; want 1 and an error", code, out.String())
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("corrupt progress file moved: %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/vimgym/vimgym/internal/progress"
+)
+
+// runImport implements --import: it merges the progress file at path into
+// the local progress, keeping the better result for each puzzle, and
+// returns the exit code.
+func runImport(path string, out io.Writer) int {
+	s, err := progress.New()
+	if err != nil {
+		fmt.Fprintf(out, "Error: %v\n", err)
+		return 1
+	}
+	if w := s.Warning(); w != nil {
+		fmt.Fprintf(out, "Warning: %v\n", w)
+	}
+	if err := s.Import(path); err != nil {
+		fmt.Fprintf(out, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(out, "Merged %s into the progress in %s\n", path, s.Dir())
+	return 0
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vimgym/vimgym/internal/progress"
+	"github.com/vimgym/vimgym/internal/puzzle"
+)
+
+func TestRunImport(t *testing.T) {
+	theirs := writeProgress(t, map[string]puzzle.StarRating{"a": puzzle.ThreeStar, "b": puzzle.OneStar})
+	ours := writeProgress(t, map[string]puzzle.StarRating{"b": puzzle.TwoStar})
+	t.Setenv(progress.DataDirEnv, ours)
+
+	var out bytes.Buffer
+	if code := runImport(filepath.Join(theirs, "progress.json"), &out); code != 0 || !strings.Contains(out.String(), "Merged ") {
+		t.Fatalf("exit code %d, output %q; want 0 and a summary", code, out.String())
+	}
+	s, err := progress.Open(ours)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, b := s.GetBest("a"), s.GetBest("b"); a.Stars != puzzle.ThreeStar || b.Stars != puzzle.TwoStar {
+		t.Errorf("merged stars a=%d b=%d, want 3 and 2", a.Stars, b.Stars)
+	}
+
+	out.Reset()
+	if code := runImport(filepath.Join(t.TempDir(), "missing.json"), &out); code != 1 || !strings.Contains(out.String(), "Error: ") {
+		t.Errorf("missing file: exit code %d, output %q; want 1 and an error", code, out.String())
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/vimgym/vimgym/internal/puzzle"
+	"github.com/vimgym/vimgym/internal/tui"
+	"github.com/vimgym/vimgym/puzzles"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(runValidate(os.Args[2:], os.Stdout))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "compare" {
+		all, _, err := loadPuzzleSet("", "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading puzzles: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(runCompare(os.Args[2:], all, os.Stdout))
+	}
+
+	puzzleID := flag.String("puzzle", "", "start in the puzzle with this `id`")
+	level := flag.Int("level", 0, "start on the puzzle list of level `n`")
+	replaceFile := flag.String("puzzles", "", "play only the puzzles in this JSON `file`, instead of the built-in set")
+	addFile := flag.String("add-puzzles", "", "add the puzzles in this JSON `file` to the built-in set")
+	importFile := flag.String("import", "", "merge the progress in this JSON `file` into yours, then exit")
+	flag.Parse()
+	if *importFile != "" {
+		os.Exit(runImport(*importFile, os.Stdout))
+	}
+	if *puzzleID != "" && *level != 0 {
+		fmt.Fprintln(os.Stderr, "Error: use either --puzzle or --level, not both")
+		os.Exit(2)
+	}
+	if *replaceFile != "" && *addFile != "" {
+		fmt.Fprintln(os.Stderr, "Error: use either --puzzles or --add-puzzles, not both")
+		os.Exit(2)
+	}
+
+	all, set, err := loadPuzzleSet(*replaceFile, *addFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading puzzles: %v\n", err)
+		os.Exit(1)
+	}
+
+	app, err := tui.NewApp(all)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	app.SetPuzzleSet(set)
+	switch {
+	case *puzzleID != "":
+		err = app.OpenPuzzle(*puzzleID)
+	case *level != 0:
+		err = app.OpenLevel(*level)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	p := tea.NewProgram(app, tea.WithAltScreen())
+	m, err := p.Run()
+	if c, ok := m.(interface{ Close() error }); ok {
+		c.Close()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// loadPuzzleSet loads the puzzles to play and describes where they came
+// from. With replaceFile set, only that file's puzzles are played; otherwise
+// the built-in puzzles are merged with the user packs and addFile, if set.
+// An empty description means the built-in puzzles (and packs) are in use.
+func loadPuzzleSet(replaceFile, addFile string) ([]puzzle.Puzzle, string, error) {
+	if replaceFile != "" {
+		all, err := puzzle.LoadFromFile(replaceFile)
+		if err != nil {
+			return nil, "", err
+		}
+		return all, replaceFile + " (instead of the built-in puzzles)", nil
+	}
+
+	all, err := puzzle.LoadFromFS(puzzles.FS, ".")
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Merge user puzzle packs from puzzle.UserPuzzleDir; a bad pack only warns.
+	all, warnings := puzzle.LoadUserPuzzles(all)
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: skipping user puzzles: %v\n", w)
+	}
+
+	if addFile == "" {
+		return all, "", nil
+	}
+	extra, err := puzzle.LoadFromFile(addFile)
+	if err != nil {
+		return nil, "", err
+	}
+	return puzzle.MergePuzzles(all, extra), addFile + " (added to the built-in puzzles)", nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	nvimclient "github.com/vimgym/vimgym/internal/nvim"
+	"github.com/vimgym/vimgym/internal/puzzle"
+)
+
+// solutionTimeout bounds how long a headless solution run may take to reach the goal.
+const solutionTimeout = 500 * time.Millisecond
+
+// packReport collects the problems found in a puzzle pack.
+type packReport struct {
+	errors   []string
+	warnings []string
+	checked  int
+}
+
+func (r *packReport) errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *packReport) warnf(format string, args ...any) {
+	r.warnings = append(r.warnings, fmt.Sprintf(format, args...))
+}
+
+// runValidate implements `vimgym validate <dir>` and returns the exit code.
+func runValidate(args []string, out io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(out, "usage: vimgym validate <dir>")
+		return 2
+	}
+	report := validatePack(args[0])
+
+	for _, w := range report.warnings {
+		fmt.Fprintf(out, "WARN  %s\n", w)
+	}
+	for _, e := range report.errors {
+		fmt.Fprintf(out, "FAIL  %s\n", e)
+	}
+	fmt.Fprintf(out, "\n%d puzzles checked: %d errors, %d warnings\n", report.checked, len(report.errors), len(report.warnings))
+	if len(report.errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// validatePack runs every pack check over the *.json files in dir.
+func validatePack(dir string) packReport {
+	var report packReport
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(paths) == 0 {
+		report.errorf("%s: no puzzle files found", dir)
+		return report
+	}
+	sort.Strings(paths)
+
+	type sourced struct {
+		file string
+		p    puzzle.Puzzle
+	}
+	var all []sourced
+	sources := make(map[string][]string)
+	for _, path := range paths {
+		file := filepath.Base(path)
+		puzzles, err := decodeStrict(path)
+		if err != nil {
+			report.errorf("%s: schema: %v", file, err)
+			continue
+		}
+		for _, p := range puzzles {
+			all = append(all, sourced{file: file, p: p})
+			sources[p.ID] = append(sources[p.ID], file)
+		}
+	}
+
+	var ids []string
+	for id, files := range sources {
+		if len(files) > 1 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		report.errorf("duplicate id %q in %s", id, strings.Join(sources[id], ", "))
+	}
+
+	for _, s := range all {
+		report.checked++
+		name := fmt.Sprintf("%s: %s", s.file, s.p.ID)
+		if s.p.Title == "" || s.p.Track <= 0 || s.p.Level <= 0 {
+			report.errorf("%s: title, track and level are required", name)
+		}
+		if err := puzzle.ValidateDefinition(s.p); err != nil {
+			report.errorf("%s: %s", name, strings.ReplaceAll(err.Error(), "\n", "; "))
+		}
+		if s.p.OptimalSolution == "" {
+			report.warnf("%s: no optimalSolution", name)
+		} else if n := puzzle.KeyCount(s.p.OptimalSolution); n > s.p.Par {
+			report.errorf("%s: par %d is below optimalSolution length %d", name, s.p.Par, n)
+		} else if n < s.p.Par {
+			report.warnf("%s: optimalSolution (%d keys) beats par %d", name, n, s.p.Par)
+		}
+	}
+
+	nv, err := nvimclient.New()
+	if err != nil {
+		report.warnf("skipping headless solution checks: %v", err)
+		return report
+	}
+	defer nv.Close()
+	for _, s := range all {
+		if s.p.OptimalSolution == "" {
+			continue
+		}
+		if err := checkSolution(nv, s.p); err != nil {
+			report.errorf("%s: %s: %v", s.file, s.p.ID, err)
+		}
+	}
+	return report
+}
+
+// decodeStrict parses a puzzle file, rejecting unknown fields.
+func decodeStrict(path string) ([]puzzle.Puzzle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var puzzles []puzzle.Puzzle
+	if err := dec.Decode(&puzzles); err != nil {
+		return nil, err
+	}
+	return puzzles, nil
+}
+
+// checkSolution types the optimal solution into Neovim and confirms it
+// clears the puzzle the way the game does: from the start cursor the demo
+// uses, the goal text (and AfterCursor, when set) must hold on two
+// consecutive reads with nothing changing in between.
+func checkSolution(nv *nvimclient.Client, p puzzle.Puzzle) error {
+	p.Before.CursorChoices = nil
+	if err := nv.LoadPuzzle(p); err != nil {
+		return fmt.Errorf("loading: %w", err)
+	}
+	var keys strings.Builder
+	for _, k := range puzzle.SplitKeys(p.OptimalSolution) {
+		if k == "<" {
+			k = "<LT>"
+		}
+		keys.WriteString(k)
+	}
+	if err := nv.Input(keys.String()); err != nil {
+		return fmt.Errorf("typing solution: %w", err)
+	}
+
+	deadline := time.Now().Add(solutionTimeout)
+	var prev string
+	for {
+		lines, row, col, _, err := nv.GetState()
+		if err != nil {
+			return fmt.Errorf("reading buffer: %w", err)
+		}
+		text := strings.Join(lines, "\n")
+		state := fmt.Sprintf("%d:%d:%s", row, col, text)
+		cursorOK := p.AfterCursor == nil || (row == p.AfterCursor.Row && col == p.AfterCursor.Col)
+		if state == prev && p.IsSolved(text) && cursorOK {
+			return nil
+		}
+		prev = state
+		if time.Now().After(deadline) {
+			if !p.IsSolved(text) {
+				return fmt.Errorf("optimalSolution does not reach the goal (got %q)", text)
+			}
+			if !cursorOK {
+				return fmt.Errorf("optimalSolution ends with the cursor at %d:%d, want afterCursor %d:%d", row, col, p.AfterCursor.Row, p.AfterCursor.Col)
+			}
+			return fmt.Errorf("optimalSolution never settles on the goal")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
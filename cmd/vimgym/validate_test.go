@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	nvimclient "github.com/vimgym/vimgym/internal/nvim"
+	"github.com/vimgym/vimgym/internal/puzzle"
+)
+
+// writePack writes files into a new pack directory.
+func writePack(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRunValidate(t *testing.T) {
+	good := `[{"id": "a", "title": "A", "track": 1, "level": 1,
+  "before": {"text": "abc", "cursor": {"row": 0, "col": 0}}, "after": {"text": "bc"},
+  "par": 1, "optimalSolution": "x"}]`
+	tests := []struct {
+		name     string
+		files    map[string]string
+		wantCode int
+		want     []string
+	}{
+		{
+			name:     "good pack",
+			files:    map[string]string{"pack.json": good},
+			wantCode: 0,
+			want:     []string{"1 puzzles checked: 0 errors"},
+		},
+		{
+			name: "bad pack",
+			files: map[string]string{
+				"a.json": good,
+				"b.json": `[{"id": "a", "title": "A again", "track": 1, "level": 1,
+  "before": {"text": "abc", "cursor": {"row": 3, "col": 0}}, "after": {"text": "abc"},
+  "par": 1, "optimalSolution": "dd"}]`,
+				"c.json": `[{"id": "c", "bogus": true}]`,
+			},
+			wantCode: 1,
+			want: []string{
+				`FAIL  duplicate id "a" in a.json, b.json`,
+				"FAIL  b.json: a: ",
+				"already matches the goal",
+				"par 1 is below optimalSolution length 2",
+				"FAIL  c.json: schema: ",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if code := runValidate([]string{writePack(t, tt.files)}, &out); code != tt.wantCode {
+				t.Errorf("exit code %d, want %d\n%s", code, tt.wantCode, out.String())
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(out.String(), w) {
+					t.Errorf("output lacks %q:\n%s", w, out.String())
+				}
+			}
+			if tt.wantCode == 0 && strings.Contains(out.String(), "FAIL") {
+				t.Errorf("good pack reported a failure:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestRunValidateUsage(t *testing.T) {
+	var out bytes.Buffer
+	if code := runValidate(nil, &out); code != 2 || !strings.Contains(out.String(), "usage:") {
+		t.Errorf("no arguments: exit code %d, output %q; want 2 and the usage", code, out.String())
+	}
+}
+
+func TestCheckSolution(t *testing.T) {
+	if _, err := exec.LookPath("nvim"); err != nil {
+		t.Skip("nvim not installed")
+	}
+	nv, err := nvimclient.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nv.Close()
+
+	base := puzzle.Puzzle{
+		Before:          puzzle.BeforeState{Text: "abc"},
+		After:           puzzle.AfterState{Text: "bc"},
+		OptimalSolution: "x",
+	}
+	tests := []struct {
+		name    string
+		edit    func(p *puzzle.Puzzle)
+		wantErr string
+	}{
+		{"reaches the goal", func(*puzzle.Puzzle) {}, ""},
+		{"wrong text", func(p *puzzle.Puzzle) { p.OptimalSolution = "$x" }, "does not reach the goal"},
+		{"cursor checked", func(p *puzzle.Puzzle) { p.AfterCursor = &puzzle.CursorPos{Col: 1} }, "afterCursor"},
+		// The demo starts from Before.Cursor, not the first cursor choice.
+		{"demo start cursor", func(p *puzzle.Puzzle) {
+			p.Before.CursorChoices = []puzzle.CursorPos{{Col: 2}, {Col: 0}}
+		}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := base
+			tt.edit(&p)
+			err := checkSolution(nv, p)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("checkSolution: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("checkSolution = %v, want an error mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -7,22 +7,34 @@ import (
 	"github.com/vimgym/vimgym/internal/puzzle"
 )
 
-// LoadPuzzle sets up the buffer with the puzzle's before state.
+// LoadPuzzle sets up the buffer with the puzzle's before state. The client
+// is reused across puzzles, so anything a previous puzzle left behind is
+// cleared first.
 func (c *Client) LoadPuzzle(p puzzle.Puzzle) error {
+	c.detachBuffer()
+	if err := c.resetState(); err != nil {
+		return err
+	}
+
 	buf, err := c.nv.CurrentBuffer()
 	if err != nil {
 		return fmt.Errorf("getting current buffer: %w", err)
 	}
 
 	// Split the before text into lines
-	lines := strings.Split(p.Before.Text, "\n")
+	lines := strings.Split(puzzle.NormalizeLineEndings(p.Before.Text), "\n")
 	byteLines := make([][]byte, len(lines))
 	for i, l := range lines {
 		byteLines[i] = []byte(l)
 	}
 
-	// Set buffer contents
-	if err := c.nv.SetBufferLines(buf, 0, -1, false, byteLines); err != nil {
+	// Set buffer contents with undo disabled, which also drops the undo
+	// history of the previous puzzle.
+	batch := c.nv.NewBatch()
+	batch.Command("set undolevels=-1")
+	batch.SetBufferLines(buf, 0, -1, false, byteLines)
+	batch.Command("set undolevels& nomodified")
+	if err := batch.Execute(); err != nil {
 		return fmt.Errorf("setting buffer lines: %w", err)
 	}
 
@@ -32,20 +44,90 @@ func (c *Client) LoadPuzzle(p puzzle.Puzzle) error {
 		return fmt.Errorf("getting current window: %w", err)
 	}
 
-	row := p.Before.Cursor.Row + 1 // convert to 1-indexed
-	col := p.Before.Cursor.Col
+	cursor := p.Before.StartCursor()
+	row := cursor.Row + 1 // convert to 1-indexed
+	col := cursor.Col
 	if err := c.nv.SetWindowCursor(win, [2]int{row, col}); err != nil {
 		return fmt.Errorf("setting cursor: %w", err)
 	}
 
-	// Ensure we're in normal mode
+	// Ensure we're in normal mode. This goes straight to Neovim so it is
+	// never counted as a user keystroke.
 	c.Input("\x1b") // Esc
 
+	// Without the subscription, callers poll after each input instead.
+	_ = c.attachBuffer()
+	return nil
+}
+
+// clearedRegisters are the writable registers wiped between puzzles.
+const clearedRegisters = `abcdefghijklmnopqrstuvwxyz0123456789"-`
+
+// saveDefaultMappings records Neovim's own global mappings at startup, for
+// restoreDefaultMappings to put back after the player's are cleared.
+const saveDefaultMappings = `
+_G.vimgym_mappings = {}
+for _, mode in ipairs({'n', 'x', 's', 'o', 'i', 'c', 'l', 't'}) do
+  _G.vimgym_mappings[mode] = vim.api.nvim_get_keymap(mode)
+end`
+
+const restoreDefaultMappings = `
+for mode, maps in pairs(_G.vimgym_mappings) do
+  for _, m in ipairs(maps) do
+    local opts = {noremap = m.noremap == 1, silent = m.silent == 1, expr = m.expr == 1,
+      nowait = m.nowait == 1, script = m.script == 1, callback = m.callback, desc = m.desc}
+    if m.replace_keycodes == 1 then
+      opts.replace_keycodes = true
+    end
+    pcall(vim.api.nvim_set_keymap, mode, m.lhs, m.rhs or '', opts)
+  end
+end`
+
+// resetOptions sets every option back to its default, keeping the ones
+// --clean sets at startup so the user's config and ShaDa file stay unused.
+const resetOptions = `
+local saved = {}
+for _, name in ipairs({'runtimepath', 'packpath', 'shadafile'}) do
+  saved[name] = vim.o[name]
+end
+vim.cmd('set all&')
+for name, value in pairs(saved) do
+  vim.o[name] = value
+end`
+
+// resetState puts Neovim back in the state a fresh instance starts a puzzle
+// in: it leaves any pending mode or macro recording, resets options, drops
+// the player's mappings and abbreviations, gives . and @: nothing to repeat,
+// and clears the registers, marks, search pattern, history and jumps of the
+// previous puzzle.
+func (c *Client) resetState() error {
+	batch := c.nv.NewBatch()
+	// Keys fed with the x flag are executed before the next call in the
+	// batch runs; Input would only queue them.
+	batch.FeedKeys("\x1b\x1b", "nx", false)
+	batch.Command("if reg_recording() != '' | call feedkeys('q', 'nx') | endif")
+	batch.ExecLua(resetOptions, nil)
+	for _, cmd := range puzzleOptions {
+		batch.Command(cmd)
+	}
+	batch.Command("mapclear | mapclear! | tmapclear | lmapclear | mapclear <buffer> | mapclear! <buffer>")
+	batch.Command("abclear | abclear <buffer>")
+	batch.ExecLua(restoreDefaultMappings, nil)
+	// A comment command line and an empty insert replace what @: and .
+	// would repeat. The buffer is replaced afterwards.
+	batch.FeedKeys(":\"\ra\x1b", "nx", false)
+	batch.Command(fmt.Sprintf("for r in split('%s', '\\zs') | call setreg(r, []) | endfor", clearedRegisters))
+	batch.Command("let @/ = '' | nohlsearch")
+	batch.Command("call histdel(':') | call histdel('/')")
+	batch.Command("delmarks! | delmarks A-Z0-9")
+	batch.Command("clearjumps")
+	if err := batch.Execute(); err != nil {
+		return fmt.Errorf("clearing editor state: %w", err)
+	}
 	return nil
 }
 
 // ResetPuzzle reloads the puzzle state (same as LoadPuzzle).
 func (c *Client) ResetPuzzle(p puzzle.Puzzle) error {
-	c.Input("\x1b\x1b") // Esc Esc
 	return c.LoadPuzzle(p)
 }
@@ -1,15 +1,137 @@
 package nvim
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os/exec"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/neovim/go-client/nvim"
 )
 
+// ErrNvimNotFound is returned by New when no nvim executable is on PATH.
+var ErrNvimNotFound = errors.New("nvim executable not found")
+
+// ErrUnsupportedNvimVersion is matched by the *VersionError New returns
+// when Neovim is older than MinVersion.
+var ErrUnsupportedNvimVersion = errors.New("unsupported Neovim version")
+
+// Version is a Neovim release version.
+type Version struct {
+	Major int `msgpack:"major"`
+	Minor int `msgpack:"minor"`
+	Patch int `msgpack:"patch"`
+}
+
+// MinVersion is the oldest Neovim release VimGym supports.
+var MinVersion = Version{Major: 0, Minor: 8, Patch: 0}
+
+func (v Version) String() string {
+	return fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Less reports whether v is an earlier release than o.
+func (v Version) Less(o Version) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor < o.Minor
+	}
+	return v.Patch < o.Patch
+}
+
+// VersionError reports a Neovim too old to run VimGym.
+type VersionError struct {
+	Detected Version
+	Required Version
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("neovim %s is too old; %s or newer is required", e.Detected, e.Required)
+}
+
+func (e *VersionError) Unwrap() error {
+	return ErrUnsupportedNvimVersion
+}
+
+// ErrTimeout is matched by the *TimeoutError returned when Neovim doesn't
+// answer an RPC call in time.
+var ErrTimeout = errors.New("neovim did not respond")
+
+// callTimeout bounds the RPC calls made on every sync, so a hung Neovim
+// can't freeze the UI.
+const callTimeout = 2 * time.Second
+
+// TimeoutError reports an RPC call that exceeded its deadline.
+type TimeoutError struct {
+	Op    string
+	After time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s: neovim did not respond within %s", e.Op, e.After)
+}
+
+func (e *TimeoutError) Unwrap() []error {
+	return []error{ErrTimeout, context.DeadlineExceeded}
+}
+
+// withTimeout runs fn, giving up with a *TimeoutError after timeout. The
+// go-client API can't be cancelled, so a hung call is left to finish (or
+// fail when the process is closed) in the background.
+func withTimeout[T any](timeout time.Duration, op string, fn func() (T, error)) (T, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	type result struct {
+		v   T
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		v, err := fn()
+		done <- result{v, err}
+	}()
+	select {
+	case r := <-done:
+		return r.v, r.err
+	case <-ctx.Done():
+		var zero T
+		return zero, &TimeoutError{Op: op, After: timeout}
+	}
+}
+
+// puzzleOptions are the option settings of puzzle mode, applied at startup
+// and again after resetState puts every option back to its default.
+var puzzleOptions = []string{
+	"set noswapfile",
+	"set nobackup",
+	"set nowritebackup",
+	"set noundofile",
+	"set shortmess+=I", // no intro message
+	// Keep insert mode deterministic: no completion sources, auto-wrapping,
+	// or abbreviations leaking in from any config.
+	"set complete= completeopt= textwidth=0 wrapmargin=0",
+	"set formatoptions-=t formatoptions-=c formatoptions-=a",
+	"set inccommand=",
+}
+
 // Client wraps a Neovim embedded instance.
 type Client struct {
-	nv *nvim.Nvim
+	nv      *nvim.Nvim
+	version Version
+
+	// changes holds a pending change notification; closed is closed by Close.
+	changes   chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+	// attached is set while the puzzle buffer is subscribed to with
+	// nvim_buf_attach.
+	attached bool
 }
 
 // New starts a new embedded Neovim process and connects via msgpack-rpc.
@@ -19,20 +141,38 @@ func New() (*Client, error) {
 		nvim.ChildProcessServe(false),
 	)
 	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("%w: install Neovim and make sure `nvim` is on your PATH (%w)", ErrNvimNotFound, err)
+		}
 		return nil, fmt.Errorf("starting nvim: %w", err)
 	}
 
+	c := &Client{nv: nv, changes: make(chan struct{}, 1), closed: make(chan struct{})}
+
 	// Register no-op handler for UI "redraw" notifications to avoid log spam
 	nv.RegisterHandler("redraw", func(...[]interface{}) {})
+	c.registerChangeHandlers()
 	go nv.Serve()
 
+	if err := nv.Eval("api_info().version", &c.version); err != nil {
+		nv.Close()
+		return nil, fmt.Errorf("getting nvim version: %w", err)
+	}
+	if c.version.Less(MinVersion) {
+		nv.Close()
+		return nil, &VersionError{Detected: c.version, Required: MinVersion}
+	}
+
 	// Set some sensible defaults for puzzle mode
 	batch := nv.NewBatch()
-	batch.Command("set noswapfile")
-	batch.Command("set nobackup")
-	batch.Command("set nowritebackup")
-	batch.Command("set noundofile")
-	batch.Command("set shortmess+=I") // no intro message
+	for _, cmd := range puzzleOptions {
+		batch.Command(cmd)
+	}
+	batch.Command("abclear")
+	// Global mappings are kept since Neovim's built-in defaults (e.g. Y as
+	// y$) are part of the puzzles; resetState restores them between puzzles.
+	batch.ExecLua(saveDefaultMappings, nil)
+	batch.Command(changeAutocmds)
 	if err := batch.Execute(); err != nil {
 		nv.Close()
 		return nil, fmt.Errorf("configuring nvim: %w", err)
@@ -45,12 +185,23 @@ func New() (*Client, error) {
 		return nil, fmt.Errorf("attaching UI: %w", err)
 	}
 
-	return &Client{nv: nv}, nil
+	return c, nil
+}
+
+// Version returns the version of the running Neovim.
+func (c *Client) Version() Version {
+	return c.version
 }
 
 // Close shuts down the Neovim process.
 func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		if c.closed != nil {
+			close(c.closed)
+		}
+	})
 	if c.nv != nil {
+		c.detachBuffer()
 		c.nv.DetachUI()
 		return c.nv.Close()
 	}
@@ -76,7 +227,22 @@ func (c *Client) Input(keys string) error {
 	}
 
 	// Queue raw input; nvim_input parses keycodes like <Esc> directly.
-	_, err := c.nv.Input(keys)
+	_, err := withTimeout(callTimeout, "sending input", func() (int, error) {
+		return c.nv.Input(keys)
+	})
+	return err
+}
+
+// Ping checks that Neovim still answers requests within timeout.
+func (c *Client) Ping(timeout time.Duration) error {
+	if c.nv == nil {
+		return nil
+	}
+	_, err := withTimeout(timeout, "ping", func() (int, error) {
+		var n int
+		err := c.nv.Eval("1", &n)
+		return n, err
+	})
 	return err
 }
 
@@ -91,6 +257,10 @@ func (c *Client) GetBufferText() (string, error) {
 
 // GetLines returns the buffer content as a slice of strings.
 func (c *Client) GetLines() ([]string, error) {
+	return withTimeout(callTimeout, "getting buffer lines", c.getLines)
+}
+
+func (c *Client) getLines() ([]string, error) {
 	buf, err := c.nv.CurrentBuffer()
 	if err != nil {
 		return nil, fmt.Errorf("getting current buffer: %w", err)
@@ -108,29 +278,123 @@ func (c *Client) GetLines() ([]string, error) {
 	return strLines, nil
 }
 
+// GetState returns the buffer lines, cursor position (0-indexed row, col)
+// and mode in a single batched RPC round trip, where GetLines, GetCursor and
+// GetMode together take five.
+func (c *Client) GetState() (lines []string, row, col int, mode string, err error) {
+	type state struct {
+		lines    []string
+		row, col int
+		mode     string
+	}
+	s, err := withTimeout(callTimeout, "getting editor state", func() (state, error) {
+		var (
+			raw [][]byte
+			pos [2]int
+			s   state
+		)
+		// Buffer and window 0 are the current ones.
+		batch := c.nv.NewBatch()
+		batch.BufferLines(0, 0, -1, false, &raw)
+		batch.WindowCursor(0, &pos)
+		batch.Eval("mode()", &s.mode)
+		if err := batch.Execute(); err != nil {
+			return state{}, fmt.Errorf("getting editor state: %w", err)
+		}
+		s.lines = make([]string, len(raw))
+		for i, l := range raw {
+			s.lines[i] = string(l)
+		}
+		// Neovim returns 1-indexed row, 0-indexed col
+		s.row, s.col = pos[0]-1, pos[1]
+		return s, nil
+	})
+	return s.lines, s.row, s.col, s.mode, err
+}
+
 // GetCursor returns the current cursor position (0-indexed row, col).
 func (c *Client) GetCursor() (int, int, error) {
+	pos, err := withTimeout(callTimeout, "getting cursor", c.getCursor)
+	return pos[0], pos[1], err
+}
+
+func (c *Client) getCursor() ([2]int, error) {
 	win, err := c.nv.CurrentWindow()
 	if err != nil {
-		return 0, 0, fmt.Errorf("getting current window: %w", err)
+		return [2]int{}, fmt.Errorf("getting current window: %w", err)
 	}
 
 	pos, err := c.nv.WindowCursor(win)
 	if err != nil {
-		return 0, 0, fmt.Errorf("getting cursor: %w", err)
+		return [2]int{}, fmt.Errorf("getting cursor: %w", err)
 	}
 
 	// Neovim returns 1-indexed row, 0-indexed col
-	return pos[0] - 1, pos[1], nil
+	return [2]int{pos[0] - 1, pos[1]}, nil
+}
+
+// GetRegister returns the contents of register name (e.g. `"` or "a").
+// Linewise contents end in a newline; an empty register returns "".
+func (c *Client) GetRegister(name string) (string, error) {
+	return withTimeout(callTimeout, "getting register", func() (string, error) {
+		var text string
+		if err := c.nv.Call("getreg", &text, name); err != nil {
+			return "", fmt.Errorf("getting register %s: %w", name, err)
+		}
+		return text, nil
+	})
+}
+
+// IsRecording returns the register a macro is being recorded into, or ""
+// when no recording is active.
+func (c *Client) IsRecording() (string, error) {
+	return withTimeout(callTimeout, "checking macro recording", func() (string, error) {
+		var reg string
+		if err := c.nv.Eval("reg_recording()", &reg); err != nil {
+			return "", fmt.Errorf("checking macro recording: %w", err)
+		}
+		return reg, nil
+	})
+}
+
+// GetVisualSelection returns the visual selection bounds (0-indexed row and
+// byte col, inclusive), ordered so the start comes first in the buffer.
+func (c *Client) GetVisualSelection() (int, int, int, int, error) {
+	sel, err := withTimeout(callTimeout, "getting visual selection", c.getVisualSelection)
+	return sel[0], sel[1], sel[2], sel[3], err
+}
+
+func (c *Client) getVisualSelection() ([4]int, error) {
+	var pos []int
+	if err := c.nv.Eval(`getpos("v")`, &pos); err != nil {
+		return [4]int{}, fmt.Errorf("getting visual start: %w", err)
+	}
+	if len(pos) < 3 {
+		return [4]int{}, fmt.Errorf("unexpected getpos result: %v", pos)
+	}
+	cursor, err := c.getCursor()
+	if err != nil {
+		return [4]int{}, err
+	}
+	row, col := cursor[0], cursor[1]
+
+	// getpos is 1-indexed for both line and col.
+	startRow, startCol := pos[1]-1, pos[2]-1
+	if startRow > row || (startRow == row && startCol > col) {
+		return [4]int{row, col, startRow, startCol}, nil
+	}
+	return [4]int{startRow, startCol, row, col}, nil
 }
 
 // GetMode returns the current Neovim mode string.
 func (c *Client) GetMode() (string, error) {
-	var mode string
-	if err := c.nv.Eval("mode()", &mode); err != nil {
-		return "", fmt.Errorf("getting mode: %w", err)
-	}
-	return mode, nil
+	return withTimeout(callTimeout, "getting mode", func() (string, error) {
+		var mode string
+		if err := c.nv.Eval("mode()", &mode); err != nil {
+			return "", fmt.Errorf("getting mode: %w", err)
+		}
+		return mode, nil
+	})
 }
 
 // ModeDisplayName converts a Neovim mode string to a display name.
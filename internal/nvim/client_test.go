@@ -0,0 +1,344 @@
+package nvim
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vimgym/vimgym/internal/puzzle"
+)
+
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	if _, err := exec.LookPath("nvim"); err != nil {
+		t.Skip("nvim not installed")
+	}
+	c, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// waitForText polls the buffer until it matches want or a timeout elapses.
+func waitForText(t *testing.T, c *Client, want string) {
+	t.Helper()
+	var got string
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+		var err error
+		if got, err = c.GetBufferText(); err != nil {
+			t.Fatal(err)
+		}
+		if got == want {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("buffer = %q, want %q", got, want)
+}
+
+func TestInsertModeTypesExactly(t *testing.T) {
+	c := newTestClient(t)
+	p := puzzle.Puzzle{Before: puzzle.BeforeState{Text: ""}}
+	if err := c.LoadPuzzle(p); err != nil {
+		t.Fatal(err)
+	}
+
+	// Brackets, quotes, a would-be abbreviation, and a long line that would
+	// wrap under textwidth must all come through verbatim.
+	typed := `foo(bar) "baz" [x] {y} teh ` + "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	if err := c.Input("i" + typed + "<C-n><Esc>"); err != nil {
+		t.Fatal(err)
+	}
+	waitForText(t, c, typed)
+}
+
+func TestVersionLess(t *testing.T) {
+	tests := []struct {
+		v, o Version
+		want bool
+	}{
+		{Version{0, 7, 2}, MinVersion, true},
+		{Version{0, 8, 0}, MinVersion, false},
+		{Version{0, 10, 1}, MinVersion, false},
+		{Version{1, 0, 0}, Version{0, 11, 0}, false},
+		{Version{0, 8, 0}, Version{0, 8, 1}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.v.Less(tt.o); got != tt.want {
+			t.Errorf("%s.Less(%s) = %v, want %v", tt.v, tt.o, got, tt.want)
+		}
+	}
+
+	err := error(&VersionError{Detected: Version{0, 7, 2}, Required: MinVersion})
+	if !errors.Is(err, ErrUnsupportedNvimVersion) {
+		t.Error("VersionError does not match ErrUnsupportedNvimVersion")
+	}
+	if !strings.Contains(err.Error(), "v0.7.2") || !strings.Contains(err.Error(), "v0.8.0") {
+		t.Errorf("error %q should name both versions", err)
+	}
+}
+
+func TestLoadPuzzleClearsPreviousState(t *testing.T) {
+	c := newTestClient(t)
+	if err := c.LoadPuzzle(puzzle.Puzzle{Before: puzzle.BeforeState{Text: "one two"}}); err != nil {
+		t.Fatal(err)
+	}
+	// Yank into a and the unnamed register, set a mark, and leave a macro
+	// recording and an undoable change behind.
+	if err := c.Input(`"ayiwmaqqdw`); err != nil {
+		t.Fatal(err)
+	}
+	waitForText(t, c, "two")
+
+	if err := c.LoadPuzzle(puzzle.Puzzle{Before: puzzle.BeforeState{Text: "next"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, reg := range []string{"a", `"`} {
+		var got string
+		if err := c.nv.Eval(fmt.Sprintf("getreg('%s')", reg), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != "" {
+			t.Errorf("register %s = %q, want it cleared", reg, got)
+		}
+	}
+	var recording string
+	var markLine int
+	if err := c.nv.Eval("reg_recording()", &recording); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.nv.Eval(`line("'a")`, &markLine); err != nil {
+		t.Fatal(err)
+	}
+	if recording != "" || markLine != 0 {
+		t.Errorf("recording %q, mark a on line %d; want neither", recording, markLine)
+	}
+
+	// Undo must not reach back into the previous puzzle.
+	if err := c.Input("u"); err != nil {
+		t.Fatal(err)
+	}
+	waitForText(t, c, "next")
+}
+
+func TestLoadPuzzleResetsSettings(t *testing.T) {
+	c := newTestClient(t)
+	if err := c.LoadPuzzle(puzzle.Puzzle{Before: puzzle.BeforeState{Text: "one two"}}); err != nil {
+		t.Fatal(err)
+	}
+	// Map a key, change an option, add an abbreviation and leave a change
+	// for . and a command line for @: to repeat.
+	if err := c.Input(":nnoremap Z dd\r:set tabstop=3\r:iabbrev zz gone\rdw"); err != nil {
+		t.Fatal(err)
+	}
+	waitForText(t, c, "two")
+
+	if err := c.LoadPuzzle(puzzle.Puzzle{Before: puzzle.BeforeState{Text: "next"}}); err != nil {
+		t.Fatal(err)
+	}
+	var mapping, abbrev, yank string
+	var tabstop int
+	for expr, dst := range map[string]any{
+		"maparg('Z', 'n')":     &mapping,
+		"maparg('zz', 'i', 1)": &abbrev,
+		"maparg('Y', 'n')":     &yank,
+		"&tabstop":             &tabstop,
+	} {
+		if err := c.nv.Eval(expr, dst); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if mapping != "" || abbrev != "" || tabstop != 8 {
+		t.Errorf("mapping %q, abbreviation %q, tabstop %d; want them reset", mapping, abbrev, tabstop)
+	}
+	if yank != "y$" {
+		t.Errorf("default Y mapping = %q, want y$ restored", yank)
+	}
+
+	// Neither . nor @: may replay the previous puzzle's commands.
+	if err := c.Input(".@:A!\x1b"); err != nil {
+		t.Fatal(err)
+	}
+	waitForText(t, c, "next!")
+}
+
+// BenchmarkStartup measures starting a fresh Neovim, the per-puzzle cost
+// before the client was reused.
+func BenchmarkStartup(b *testing.B) {
+	if _, err := exec.LookPath("nvim"); err != nil {
+		b.Skip("nvim not installed")
+	}
+	for b.Loop() {
+		c, err := New()
+		if err != nil {
+			b.Fatal(err)
+		}
+		c.Close()
+	}
+}
+
+// BenchmarkLoadPuzzle measures switching puzzles on a reused client.
+func BenchmarkLoadPuzzle(b *testing.B) {
+	if _, err := exec.LookPath("nvim"); err != nil {
+		b.Skip("nvim not installed")
+	}
+	c, err := New()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer c.Close()
+	p := puzzle.Puzzle{Before: puzzle.BeforeState{Text: "hello world"}}
+	for b.Loop() {
+		if err := c.LoadPuzzle(p); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	got, err := withTimeout(time.Second, "fast", func() (int, error) { return 7, nil })
+	if got != 7 || err != nil {
+		t.Errorf("fast call = %d, %v; want 7, nil", got, err)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	_, err = withTimeout(10*time.Millisecond, "slow", func() (int, error) {
+		<-release
+		return 0, nil
+	})
+	var te *TimeoutError
+	if !errors.As(err, &te) || te.Op != "slow" || !errors.Is(err, ErrTimeout) {
+		t.Errorf("slow call error = %v, want a TimeoutError matching ErrTimeout", err)
+	}
+}
+
+func TestGetRegister(t *testing.T) {
+	c := newTestClient(t)
+	if err := c.LoadPuzzle(puzzle.Puzzle{Before: puzzle.BeforeState{Text: "one\ntwo"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := c.GetRegister(`"`); err != nil || got != "" {
+		t.Errorf("empty register = %q, %v; want \"\"", got, err)
+	}
+	if err := c.Input(`yj"ayiw`); err != nil {
+		t.Fatal(err)
+	}
+	waitForText(t, c, "one\ntwo")
+	if got, err := c.GetRegister("a"); err != nil || got != "one" {
+		t.Errorf(`register a = %q, %v; want "one"`, got, err)
+	}
+	if got, err := c.GetRegister("0"); err != nil || got != "one\ntwo\n" {
+		t.Errorf(`register 0 = %q, %v; want linewise "one\ntwo\n"`, got, err)
+	}
+}
+
+func TestGetVisualSelection(t *testing.T) {
+	c := newTestClient(t)
+	p := puzzle.Puzzle{Before: puzzle.BeforeState{Text: "one\ntwo", Cursor: puzzle.CursorPos{Row: 1, Col: 2}}}
+	if err := c.LoadPuzzle(p); err != nil {
+		t.Fatal(err)
+	}
+	// Selecting backwards still reports the start first.
+	if err := c.Input("<C-v>kh"); err != nil {
+		t.Fatal(err)
+	}
+	r1, c1, r2, c2, err := c.GetVisualSelection()
+	if err != nil || r1 != 0 || c1 != 1 || r2 != 1 || c2 != 2 {
+		t.Errorf("GetVisualSelection = %d,%d %d,%d, %v; want 0,1 1,2", r1, c1, r2, c2, err)
+	}
+}
+
+func TestGetState(t *testing.T) {
+	c := newTestClient(t)
+	p := puzzle.Puzzle{Before: puzzle.BeforeState{Text: "one\ntwo", Cursor: puzzle.CursorPos{Row: 1, Col: 2}}}
+	if err := c.LoadPuzzle(p); err != nil {
+		t.Fatal(err)
+	}
+	lines, row, col, mode, err := c.GetState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(lines, "\n") != "one\ntwo" || row != 1 || col != 2 || mode != "n" {
+		t.Errorf("GetState = %q, %d, %d, %q; want one/two at 1,2 in n", lines, row, col, mode)
+	}
+}
+
+// BenchmarkSyncReads compares one batched GetState against the separate
+// GetLines, GetCursor and GetMode calls a sync used to make.
+func BenchmarkSyncReads(b *testing.B) {
+	if _, err := exec.LookPath("nvim"); err != nil {
+		b.Skip("nvim not installed")
+	}
+	c, err := New()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer c.Close()
+	if err := c.LoadPuzzle(puzzle.Puzzle{Before: puzzle.BeforeState{Text: "one\ntwo"}}); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("batched", func(b *testing.B) {
+		for range b.N {
+			if _, _, _, _, err := c.GetState(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("separate", func(b *testing.B) {
+		for range b.N {
+			if _, err := c.GetLines(); err != nil {
+				b.Fatal(err)
+			}
+			if _, _, err := c.GetCursor(); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := c.GetMode(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestWaitForChange(t *testing.T) {
+	c := newTestClient(t)
+	if err := c.LoadPuzzle(puzzle.Puzzle{Before: puzzle.BeforeState{Text: "one"}}); err != nil {
+		t.Fatal(err)
+	}
+	if !c.LiveUpdates() {
+		t.Skip("buffer attach unavailable; polling is used instead")
+	}
+	// Drain anything the load itself reported.
+	for drained := false; !drained; {
+		select {
+		case <-c.changes:
+		case <-time.After(50 * time.Millisecond):
+			drained = true
+		}
+	}
+
+	if err := c.Input("x"); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan bool, 1)
+	go func() { done <- c.WaitForChange() }()
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Error("WaitForChange returned false on an open client")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no change reported after x")
+	}
+
+	c.Close()
+	if c.WaitForChange() {
+		t.Error("WaitForChange returned true after Close")
+	}
+}
@@ -0,0 +1,73 @@
+package nvim
+
+import "fmt"
+
+// changedEvent is the notification the autocmds below send when the cursor,
+// mode, macro recording or a register changes; buffer text changes arrive
+// as nvim_buf_attach events.
+const changedEvent = "vimgym_changed"
+
+// changeAutocmds notify on everything a sync reads besides the buffer text.
+const changeAutocmds = "augroup vimgym | autocmd! | " +
+	"autocmd CursorMoved,CursorMovedI,ModeChanged,RecordingEnter,RecordingLeave,TextYankPost * call rpcnotify(0, '" + changedEvent + "') | " +
+	"augroup END"
+
+// registerChangeHandlers routes buffer and change notifications to notify.
+// Their arguments are not needed: any event just means the state is stale.
+func (c *Client) registerChangeHandlers() {
+	for _, method := range []string{"nvim_buf_lines_event", "nvim_buf_changedtick_event", "nvim_buf_detach_event", changedEvent} {
+		c.nv.RegisterHandler(method, func() { c.notify() })
+	}
+}
+
+// notify records a change without blocking; changes that arrive before the
+// last one is picked up are coalesced into it.
+func (c *Client) notify() {
+	select {
+	case c.changes <- struct{}{}:
+	default:
+	}
+}
+
+// WaitForChange blocks until Neovim reports a change to the buffer, cursor
+// or mode, and returns false once the client is closed.
+func (c *Client) WaitForChange() bool {
+	select {
+	case <-c.closed:
+		return false
+	default:
+	}
+	select {
+	case <-c.changes:
+		return true
+	case <-c.closed:
+		return false
+	}
+}
+
+// LiveUpdates reports whether changes are being pushed by Neovim, so the
+// caller can rely on WaitForChange instead of polling after each input.
+func (c *Client) LiveUpdates() bool {
+	return c.attached
+}
+
+// attachBuffer subscribes to changes of the current buffer. On failure the
+// client keeps working and callers fall back to polling.
+func (c *Client) attachBuffer() error {
+	attached, err := c.nv.AttachBuffer(0, false, map[string]interface{}{})
+	if err != nil {
+		return fmt.Errorf("attaching to buffer: %w", err)
+	}
+	c.attached = attached
+	return nil
+}
+
+// detachBuffer ends the subscription, so loading the next puzzle doesn't
+// report its setup as changes.
+func (c *Client) detachBuffer() {
+	if !c.attached {
+		return
+	}
+	c.attached = false
+	_, _ = c.nv.DetachBuffer(0)
+}
@@ -0,0 +1,53 @@
+package progress
+
+import "github.com/vimgym/vimgym/internal/puzzle"
+
+// Winner identifies which side of a comparison has the better result.
+type Winner int
+
+const (
+	Tie Winner = iota
+	WinnerA
+	WinnerB
+)
+
+// PuzzleComparison pairs two results for the same puzzle.
+type PuzzleComparison struct {
+	Puzzle puzzle.Puzzle
+	A      PuzzleResult
+	B      PuzzleResult
+	Winner Winner
+}
+
+// Comparison is a head-to-head summary of two stores.
+type Comparison struct {
+	Puzzles []PuzzleComparison
+	WinsA   int
+	WinsB   int
+	Ties    int
+}
+
+// Compare compares two stores puzzle by puzzle using the same "better result"
+// rule as SetBest. Puzzles neither side has solved are skipped.
+func Compare(a, b *Store, allPuzzles []puzzle.Puzzle) Comparison {
+	var c Comparison
+	for _, p := range allPuzzles {
+		ra, rb := a.GetBest(p.ID), b.GetBest(p.ID)
+		if ra.Stars == puzzle.NoStar && rb.Stars == puzzle.NoStar {
+			continue
+		}
+		pc := PuzzleComparison{Puzzle: p, A: ra, B: rb}
+		switch {
+		case isBetter(ra, rb):
+			pc.Winner = WinnerA
+			c.WinsA++
+		case isBetter(rb, ra):
+			pc.Winner = WinnerB
+			c.WinsB++
+		default:
+			c.Ties++
+		}
+		c.Puzzles = append(c.Puzzles, pc)
+	}
+	return c
+}
@@ -0,0 +1,164 @@
+package progress
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Export formats accepted by Store.Export.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// analyticsExport is the document written by ExportAnalytics.
+type analyticsExport struct {
+	ExportedAt time.Time               `json:"exportedAt"`
+	Note       string                  `json:"note"`
+	Results    map[string]PuzzleResult `json:"results"`
+	History    map[string][]Attempt    `json:"history"`
+}
+
+// ExportAnalytics writes everything the store knows (best results, attempt
+// counts, timings and solve history) to a JSON file at path.
+// The export is purely local; nothing is sent over the network.
+func (s *Store) ExportAnalytics(path string) error {
+	doc := analyticsExport{
+		ExportedAt: now(),
+		Note:       "Local VimGym analytics export. No data leaves this machine.",
+		Results:    s.Results,
+		History:    s.Solves,
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling analytics: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing analytics: %w", err)
+	}
+	return nil
+}
+
+// Export writes the store's progress to path. FormatJSON writes the same
+// document as progress.json, so it can be loaded back as-is; FormatCSV
+// writes one row per puzzle with its ID, best stars, keystrokes and when
+// the best result was recorded.
+func (s *Store) Export(path, format string) error {
+	var data []byte
+	var err error
+	switch format {
+	case FormatJSON:
+		data, err = json.MarshalIndent(s, "", "  ")
+	case FormatCSV:
+		data, err = s.csvExport()
+	default:
+		return fmt.Errorf("unknown export format %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("encoding %s export: %w", format, err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing export: %w", err)
+	}
+	return nil
+}
+
+// csvExport renders the best results as CSV, sorted by puzzle ID.
+func (s *Store) csvExport() ([]byte, error) {
+	ids := make([]string, 0, len(s.Results))
+	for id := range s.Results {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"puzzleID", "stars", "keystrokes", "timestamp"})
+	for _, id := range ids {
+		r := s.Results[id]
+		timestamp := ""
+		if !r.CompletedAt.IsZero() {
+			timestamp = r.CompletedAt.Format(time.RFC3339)
+		}
+		w.Write([]string{id, strconv.Itoa(int(r.Stars)), strconv.Itoa(r.Keystrokes), timestamp})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// Import merges the progress file at path (such as a JSON export from
+// another machine) into the store and saves the result. Each puzzle keeps
+// whichever best result is better, by the same rule as SetBest; attempt
+// counts and time spent keep the larger value, solve histories are combined
+// without duplicating solves present in both, and favorites are added.
+func (s *Store) Import(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading import: %w", err)
+	}
+	var other Store
+	if err := json.Unmarshal(data, &other); err != nil {
+		return fmt.Errorf("parsing import: %w", err)
+	}
+	other.migrate()
+
+	for id, theirs := range other.Results {
+		ours, ok := s.Results[id]
+		if !ok {
+			theirs.NeedsReview = needsReview(theirs)
+			s.Results[id] = theirs
+			continue
+		}
+		// Attempts made since the kept best, so that AttemptsAtBest can be
+		// rebased onto the merged attempt count.
+		sinceBest := ours.Attempts - ours.AttemptsAtBest
+		if isBetter(theirs, ours) {
+			ours.Stars = theirs.Stars
+			ours.Keystrokes = theirs.Keystrokes
+			ours.CompletedAt = theirs.CompletedAt
+			ours.BestReplay = theirs.BestReplay
+			ours.BestAttempts = theirs.BestAttempts
+			sinceBest = theirs.Attempts - theirs.AttemptsAtBest
+		}
+		ours.Perfect = ours.Perfect || theirs.Perfect
+		ours.Attempts = max(ours.Attempts, theirs.Attempts)
+		ours.AttemptsAtBest = max(ours.Attempts-sinceBest, 0)
+		ours.TimeSpent = max(ours.TimeSpent, theirs.TimeSpent)
+		ours.NeedsReview = needsReview(ours)
+		s.Results[id] = ours
+	}
+
+	for id := range other.Favorites {
+		if !s.IsFavorite(id) {
+			s.ToggleFavorite(id)
+		}
+	}
+	for id, theirs := range other.Solves {
+		s.Solves[id] = mergeHistory(s.Solves[id], theirs)
+		s.pruneHistory(id)
+	}
+	return s.Save()
+}
+
+// mergeHistory combines two solve histories oldest first, dropping solves
+// from b recorded at the same moment as one in a.
+func mergeHistory(a, b []Attempt) []Attempt {
+	seen := make(map[time.Time]bool, len(a))
+	merged := append([]Attempt(nil), a...)
+	for _, at := range a {
+		seen[at.At] = true
+	}
+	for _, at := range b {
+		if !seen[at.At] {
+			merged = append(merged, at)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].At.Before(merged[j].At) })
+	return merged
+}
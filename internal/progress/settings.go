@@ -0,0 +1,93 @@
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const settingsFile = "settings.json"
+
+// Settings holds user preferences, stored next to progress.json.
+type Settings struct {
+	dir string
+	// ShowTimer shows the live solving clock while playing.
+	ShowTimer bool `json:"showTimer"`
+	// ColorblindStars draws earned and unearned stars with different glyphs
+	// instead of relying on color alone.
+	ColorblindStars bool `json:"colorblindStars"`
+	// AutoAdvance moves on to the next puzzle shortly after a clear.
+	AutoAdvance bool `json:"autoAdvance"`
+	// Mastery only advances to the next puzzle after a three-star clear;
+	// anything less offers a retry (skipping stays possible).
+	Mastery bool `json:"mastery"`
+	// HideSolutions disables solution reveal and copy, for challenge runs.
+	HideSolutions bool `json:"hideSolutions"`
+	// FreeHints scores solves without penalty for viewing the hint or solution.
+	FreeHints bool `json:"freeHints"`
+	// Theme names the color theme (empty means the default).
+	Theme string `json:"theme,omitempty"`
+	// TrackStarGates maps a track to the total stars that must be earned in
+	// the previous track before its first level unlocks. Unset tracks are
+	// gated by level unlocks alone.
+	TrackStarGates map[int]int `json:"trackStarGates,omitempty"`
+	// TutorialDone records that the first-run tutorial was finished or
+	// skipped, so it isn't shown again.
+	TutorialDone bool `json:"tutorialDone"`
+}
+
+// DefaultSettings returns the preferences used when none are saved.
+func DefaultSettings() Settings {
+	return Settings{ShowTimer: true}
+}
+
+// DataDirEnv names the environment variable that overrides DataDir.
+const DataDirEnv = "VIMGYM_DATA_DIR"
+
+// DataDir returns the directory holding progress and settings, creating it
+// if needed: $VIMGYM_DATA_DIR when set, otherwise ~/.vimgym.
+func DataDir() (string, error) {
+	dir := os.Getenv(DataDirEnv)
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("getting home dir: %w", err)
+		}
+		dir = filepath.Join(home, ".vimgym")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating vimgym dir: %w", err)
+	}
+	return dir, nil
+}
+
+// LoadSettings reads the settings stored in dir. Missing fields, or a missing
+// file, fall back to DefaultSettings.
+func LoadSettings(dir string) (*Settings, error) {
+	s := DefaultSettings()
+	s.dir = dir
+	data, err := os.ReadFile(filepath.Join(dir, settingsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &s, nil
+		}
+		return &s, fmt.Errorf("reading settings: %w", err)
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return &s, fmt.Errorf("parsing settings: %w", err)
+	}
+	return &s, nil
+}
+
+// Save writes the settings to disk.
+func (s *Settings) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling settings: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(s.dir, settingsFile), data, 0644); err != nil {
+		return fmt.Errorf("writing settings: %w", err)
+	}
+	return nil
+}
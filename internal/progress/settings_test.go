@@ -0,0 +1,79 @@
+package progress
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/vimgym/vimgym/internal/puzzle"
+)
+
+func TestLoadSettingsDefaults(t *testing.T) {
+	s, err := LoadSettings(t.TempDir())
+	if err != nil {
+		t.Fatalf("LoadSettings: %v", err)
+	}
+	if !reflect.DeepEqual(*s, Settings{dir: s.dir, ShowTimer: true}) {
+		t.Errorf("LoadSettings with no file = %+v, want defaults", *s)
+	}
+}
+
+func TestSettingsRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := LoadSettings(dir)
+	s.ShowTimer = false
+	s.AutoAdvance = true
+	s.TutorialDone = true
+	s.Mastery = true
+	s.TrackStarGates = map[int]int{2: 20, 3: 45}
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, err := LoadSettings(dir)
+	if err != nil {
+		t.Fatalf("LoadSettings: %v", err)
+	}
+	if got.ShowTimer || !got.AutoAdvance || got.HideSolutions || !got.TutorialDone || !got.Mastery ||
+		!reflect.DeepEqual(got.TrackStarGates, map[int]int{2: 20, 3: 45}) {
+		t.Errorf("reloaded settings = %+v", *got)
+	}
+}
+
+func TestLoadSettingsCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, settingsFile), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := LoadSettings(dir)
+	if err == nil {
+		t.Error("expected an error for a corrupt settings file")
+	}
+	if !s.ShowTimer {
+		t.Error("corrupt settings should fall back to defaults")
+	}
+}
+
+func TestDataDirEnv(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	t.Setenv(DataDirEnv, dir)
+
+	s, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if s.Dir() != dir {
+		t.Fatalf("store dir = %q, want %q", s.Dir(), dir)
+	}
+	s.SetBest("a", 3, 4, nil)
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, progressFile)); err != nil {
+		t.Errorf("progress not saved under %s: %v", DataDirEnv, err)
+	}
+	if got, err := puzzle.UserPuzzleDir(); err != nil || got != filepath.Join(dir, "puzzles") {
+		t.Errorf("UserPuzzleDir = %q, %v; want the puzzles directory under %s", got, err, DataDirEnv)
+	}
+}
@@ -4,51 +4,165 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
+	"sort"
+	"time"
 
 	"github.com/vimgym/vimgym/internal/puzzle"
 )
 
 const progressFile = "progress.json"
 
+// schemaVersion is the progress file format written by Save. Load passes
+// older files through migrate:
+//
+//	0 → 1: added the version field. Solved results missing a completion
+//	       time take it from their latest matching solve in the history,
+//	       solved results with no attempt count get at least one, and
+//	       review flags are recomputed.
+//	1 → 2: review flags are recomputed by the attempts-to-best rule, which
+//	       clears flags set by loads and resets alone.
+const schemaVersion = 2
+
+// reviewStars is the star rating at or below which a solve is flagged for review.
+const reviewStars = puzzle.OneStar
+
+// reviewAttempts is the number of attempts spent reaching a best result at
+// which it is flagged for review.
+const reviewAttempts = 5
+
+// defaultHistoryLimit caps the solve history kept per puzzle unless
+// Store.HistoryLimit overrides it.
+const defaultHistoryLimit = 20
+
+// now is the clock used for timestamps (replaced in tests).
+var now = time.Now
+
 // PuzzleResult stores the best result for a puzzle.
 type PuzzleResult struct {
 	Stars      puzzle.StarRating `json:"stars"`
 	Keystrokes int               `json:"keystrokes"`
+	// NeedsReview marks a weak solve that should be revisited.
+	NeedsReview bool `json:"needsReview,omitempty"`
+	// CompletedAt is when the best result was recorded.
+	CompletedAt time.Time `json:"completedAt,omitzero"`
+	// Attempts counts how many times the puzzle was started or reset.
+	Attempts int `json:"attempts,omitempty"`
+	// BestAttempts is how many attempts it took to reach the best result
+	// since the previous one; AttemptsAtBest is Attempts when it was set.
+	BestAttempts   int `json:"bestAttempts,omitempty"`
+	AttemptsAtBest int `json:"attemptsAtBest,omitempty"`
+	// TimeSpent is the total solving time accumulated across clears.
+	TimeSpent time.Duration `json:"timeSpent,omitempty"`
+	// Perfect is set once a solve matched or beat the optimal solution.
+	Perfect bool `json:"perfect,omitempty"`
+	// BestReplay is the command sequence of the best result, if recorded.
+	BestReplay []string `json:"bestReplay,omitempty"`
+}
+
+// Attempt is a single recorded solve of a puzzle.
+type Attempt struct {
+	At         time.Time         `json:"at"`
+	Keystrokes int               `json:"keystrokes"`
+	Par        int               `json:"par"`
+	Stars      puzzle.StarRating `json:"stars"`
+	Time       time.Duration     `json:"time,omitempty"`
+	// Assisted marks solves where the solution was viewed or stepped through.
+	Assisted bool `json:"assisted,omitempty"`
+	// Revealed is how many solution keys were revealed one at a time.
+	Revealed int `json:"revealed,omitempty"`
+}
+
+// OverPar returns how many keystrokes the solve took beyond par.
+func (a Attempt) OverPar() int {
+	return a.Keystrokes - a.Par
 }
 
 // Store manages progress persistence.
 type Store struct {
-	dir     string
-	Results map[string]PuzzleResult `json:"results"` // keyed by puzzle ID
+	dir string
+	// Version is the schema version of the loaded file; Save writes schemaVersion.
+	Version int                     `json:"version"`
+	Results map[string]PuzzleResult `json:"results"`           // keyed by puzzle ID
+	Solves  map[string][]Attempt    `json:"history,omitempty"` // keyed by puzzle ID, oldest first
+	// HistoryLimit is the number of solves kept per puzzle (0 = default).
+	HistoryLimit int `json:"historyLimit,omitempty"`
+	// Favorites holds the IDs of puzzles bookmarked to revisit.
+	Favorites map[string]bool `json:"favorites,omitempty"`
+
+	// warning is a non-fatal load problem reported by Warning.
+	warning error
+	// gates are the track star gates from the settings (see SetTrackStarGates).
+	gates map[int]int
+}
+
+// Open loads a read-only view of the progress stored in dir. Unlike Load,
+// it never moves files: an unparseable progress file is reported as an
+// error and left where it is.
+func Open(dir string) (*Store, error) {
+	s := &Store{
+		dir:     dir,
+		Results: make(map[string]PuzzleResult),
+		Solves:  make(map[string][]Attempt),
+	}
+	if err := s.load(false); err != nil {
+		return nil, err
+	}
+	return s, nil
 }
 
 // New creates a new progress store.
 func New() (*Store, error) {
-	home, err := os.UserHomeDir()
+	dir, err := DataDir()
 	if err != nil {
-		return nil, fmt.Errorf("getting home dir: %w", err)
-	}
-
-	dir := filepath.Join(home, ".vimgym")
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return nil, fmt.Errorf("creating vimgym dir: %w", err)
+		return nil, err
 	}
 
 	s := &Store{
 		dir:     dir,
 		Results: make(map[string]PuzzleResult),
+		Solves:  make(map[string][]Attempt),
 	}
 
-	// Load existing progress if available
-	s.Load()
+	// Load existing progress if available. A corrupt file has already been
+	// set aside by Load; remember why so the caller can warn about it.
+	s.warning = s.Load()
 
 	return s, nil
 }
 
-// Load reads progress from disk.
+// Warning returns the non-fatal problem New hit while loading progress,
+// such as a corrupt file that was backed up and replaced, or nil.
+func (s *Store) Warning() error {
+	return s.warning
+}
+
+// CorruptFileError reports a progress file that couldn't be parsed. Load
+// moves it to Backup and continues with empty progress.
+type CorruptFileError struct {
+	Backup string
+	Err    error
+}
+
+func (e *CorruptFileError) Error() string {
+	return fmt.Sprintf("progress file was corrupt (%v); moved it to %s and started fresh", e.Err, e.Backup)
+}
+
+func (e *CorruptFileError) Unwrap() error {
+	return e.Err
+}
+
+// Load reads progress from disk. An unparseable file is moved aside and
+// the store starts empty (see CorruptFileError).
 func (s *Store) Load() error {
+	return s.load(true)
+}
+
+// load reads progress from disk, backing up an unparseable file only when
+// setAside is set.
+func (s *Store) load(setAside bool) error {
 	path := filepath.Join(s.dir, progressFile)
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -59,31 +173,130 @@ func (s *Store) Load() error {
 	}
 
 	if err := json.Unmarshal(data, s); err != nil {
-		return fmt.Errorf("parsing progress: %w", err)
+		if !setAside {
+			return fmt.Errorf("parsing progress: %w", err)
+		}
+		return s.recoverCorrupt(path, err)
 	}
 	if s.Results == nil {
 		s.Results = make(map[string]PuzzleResult)
 	}
+	s.migrate()
+	for id := range s.Solves {
+		s.pruneHistory(id)
+	}
 	return nil
 }
 
+// recoverCorrupt moves an unparseable progress file aside and resets the
+// store to empty progress.
+func (s *Store) recoverCorrupt(path string, parseErr error) error {
+	backup := path + ".corrupt-" + now().Format("20060102-150405")
+	if err := os.Rename(path, backup); err != nil {
+		return fmt.Errorf("parsing progress: %w (backing it up failed: %v)", parseErr, err)
+	}
+	*s = Store{
+		dir:     s.dir,
+		Results: make(map[string]PuzzleResult),
+		Solves:  make(map[string][]Attempt),
+	}
+	return &CorruptFileError{Backup: backup, Err: parseErr}
+}
+
+// migrate upgrades a store parsed from an older file to the current schema.
+// Files from a newer version are left as they are.
+func (s *Store) migrate() {
+	if s.Results == nil {
+		s.Results = make(map[string]PuzzleResult)
+	}
+	if s.Solves == nil {
+		s.Solves = make(map[string][]Attempt)
+	}
+	if s.Version < 1 {
+		for id, r := range s.Results {
+			if r.Stars > puzzle.NoStar {
+				if r.CompletedAt.IsZero() {
+					r.CompletedAt = s.bestSolveTime(id, r)
+				}
+				r.Attempts = max(r.Attempts, 1)
+			}
+			s.Results[id] = r
+		}
+	}
+	if s.Version < 2 {
+		for id, r := range s.Results {
+			r.NeedsReview = needsReview(r)
+			s.Results[id] = r
+		}
+	}
+	s.Version = max(s.Version, schemaVersion)
+}
+
+// bestSolveTime returns when the latest recorded solve matching r happened,
+// or the zero time if the history has none.
+func (s *Store) bestSolveTime(puzzleID string, r PuzzleResult) time.Time {
+	h := s.Solves[puzzleID]
+	for i := len(h) - 1; i >= 0; i-- {
+		if h[i].Stars == r.Stars && h[i].Keystrokes == r.Keystrokes {
+			return h[i].At
+		}
+	}
+	return time.Time{}
+}
+
 // Save writes progress to disk.
 func (s *Store) Save() error {
+	s.Version = max(s.Version, schemaVersion)
 	data, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshaling progress: %w", err)
 	}
 
 	path := filepath.Join(s.dir, progressFile)
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	if err := writeFileAtomic(path, data, 0644); err != nil {
 		return fmt.Errorf("writing progress: %w", err)
 	}
 	return nil
 }
 
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so a crash mid-write leaves the previous file intact.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Dir returns the directory the store reads and writes.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// IsEmpty reports whether nothing has been played yet: no puzzle has been
+// attempted or solved.
+func (s *Store) IsEmpty() bool {
+	return len(s.Results) == 0 && len(s.Solves) == 0
+}
+
 // Reset clears all progress and persists the empty state.
 func (s *Store) Reset() error {
 	s.Results = make(map[string]PuzzleResult)
+	s.Solves = make(map[string][]Attempt)
 	return s.Save()
 }
 
@@ -92,24 +305,282 @@ func (s *Store) GetBest(puzzleID string) PuzzleResult {
 	return s.Results[puzzleID]
 }
 
-// SetBest updates the best result for a puzzle if it's better than existing.
-func (s *Store) SetBest(puzzleID string, stars puzzle.StarRating, keystrokes int) {
+// SetBest updates the best result for a puzzle if it's better than existing,
+// keeping replay as the winning key sequence.
+// The review flag is refreshed on every call based on the best result.
+func (s *Store) SetBest(puzzleID string, stars puzzle.StarRating, keystrokes int, replay []string) {
 	existing, ok := s.Results[puzzleID]
-	if !ok || stars > existing.Stars || (stars == existing.Stars && keystrokes < existing.Keystrokes) {
-		s.Results[puzzleID] = PuzzleResult{
-			Stars:      stars,
-			Keystrokes: keystrokes,
+	if !ok || isBetter(PuzzleResult{Stars: stars, Keystrokes: keystrokes}, existing) {
+		existing.Stars = stars
+		existing.Keystrokes = keystrokes
+		existing.CompletedAt = now()
+		existing.BestReplay = append([]string(nil), replay...)
+		existing.BestAttempts = existing.Attempts - existing.AttemptsAtBest
+		existing.AttemptsAtBest = existing.Attempts
+	}
+	existing.NeedsReview = needsReview(existing)
+	s.Results[puzzleID] = existing
+}
+
+// MarkPerfect records that a puzzle was solved in at most the optimal
+// solution's keystrokes. Stars are unaffected, so level and track ratings
+// still top out at three.
+func (s *Store) MarkPerfect(puzzleID string) {
+	r := s.Results[puzzleID]
+	r.Perfect = true
+	s.Results[puzzleID] = r
+}
+
+// GetReplay returns the key sequence of the best result, or nil if none was recorded.
+func (s *Store) GetReplay(puzzleID string) []string {
+	return s.Results[puzzleID].BestReplay
+}
+
+// isBetter reports whether result a beats result b: more stars, or equal
+// stars with fewer keystrokes.
+func isBetter(a, b PuzzleResult) bool {
+	return a.Stars > b.Stars || (a.Stars == b.Stars && a.Keystrokes < b.Keystrokes)
+}
+
+// needsReview reports whether a result is weak enough to be revisited: a
+// one-star best, or a best short of three stars that took reviewAttempts or
+// more attempts to reach. Three stars never need review.
+func needsReview(r PuzzleResult) bool {
+	if r.Stars < puzzle.OneStar || r.Stars >= puzzle.ThreeStar {
+		return false
+	}
+	return r.Stars <= reviewStars || r.BestAttempts >= reviewAttempts
+}
+
+// RecordAttempt increments the attempt counter for a puzzle.
+func (s *Store) RecordAttempt(puzzleID string) {
+	r := s.Results[puzzleID]
+	r.Attempts++
+	s.Results[puzzleID] = r
+}
+
+// AddTime accumulates solving time for a puzzle.
+func (s *Store) AddTime(puzzleID string, d time.Duration) {
+	r := s.Results[puzzleID]
+	r.TimeSpent += d
+	s.Results[puzzleID] = r
+}
+
+// RecordSolve appends a solve to a puzzle's history, stamping it with the
+// current time if unset and dropping the oldest entries beyond the limit.
+func (s *Store) RecordSolve(puzzleID string, a Attempt) {
+	if a.At.IsZero() {
+		a.At = now()
+	}
+	s.Solves[puzzleID] = append(s.Solves[puzzleID], a)
+	s.pruneHistory(puzzleID)
+}
+
+// historyLimit returns the effective per-puzzle history cap.
+func (s *Store) historyLimit() int {
+	if s.HistoryLimit > 0 {
+		return s.HistoryLimit
+	}
+	return defaultHistoryLimit
+}
+
+func (s *Store) pruneHistory(puzzleID string) {
+	h := s.Solves[puzzleID]
+	if limit := s.historyLimit(); len(h) > limit {
+		s.Solves[puzzleID] = append([]Attempt(nil), h[len(h)-limit:]...)
+	}
+}
+
+// History returns the recorded solves for a puzzle, oldest first.
+func (s *Store) History(puzzleID string) []Attempt {
+	return s.Solves[puzzleID]
+}
+
+// OverParSolves returns how many recorded solves of a puzzle went over par.
+func (s *Store) OverParSolves(puzzleID string) int {
+	n := 0
+	for _, a := range s.Solves[puzzleID] {
+		if a.OverPar() > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// RecentHistory returns the latest n solves across all puzzles, oldest first.
+func (s *Store) RecentHistory(n int) []Attempt {
+	var all []Attempt
+	for _, h := range s.Solves {
+		all = append(all, h...)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].At.Before(all[j].At)
+	})
+	if n > 0 && len(all) > n {
+		all = all[len(all)-n:]
+	}
+	return all
+}
+
+// ReviewQueue returns the puzzles flagged for review, in the given order.
+func (s *Store) ReviewQueue(allPuzzles []puzzle.Puzzle) []puzzle.Puzzle {
+	var queue []puzzle.Puzzle
+	for _, p := range allPuzzles {
+		if s.GetBest(p.ID).NeedsReview {
+			queue = append(queue, p)
+		}
+	}
+	return queue
+}
+
+// ToggleFavorite bookmarks a puzzle, or removes the bookmark if it is set.
+func (s *Store) ToggleFavorite(puzzleID string) {
+	if s.Favorites[puzzleID] {
+		delete(s.Favorites, puzzleID)
+		return
+	}
+	if s.Favorites == nil {
+		s.Favorites = make(map[string]bool)
+	}
+	s.Favorites[puzzleID] = true
+}
+
+// IsFavorite reports whether a puzzle is bookmarked.
+func (s *Store) IsFavorite(puzzleID string) bool {
+	return s.Favorites[puzzleID]
+}
+
+// FavoritePuzzles returns the bookmarked puzzles, in allPuzzles order.
+func (s *Store) FavoritePuzzles(allPuzzles []puzzle.Puzzle) []puzzle.Puzzle {
+	var favorites []puzzle.Puzzle
+	for _, p := range allPuzzles {
+		if s.IsFavorite(p.ID) {
+			favorites = append(favorites, p)
+		}
+	}
+	return favorites
+}
+
+// RandomUnsolved picks a random unsolved puzzle from an unlocked level.
+// It returns false when every accessible puzzle is already solved.
+func (s *Store) RandomUnsolved(allPuzzles []puzzle.Puzzle) (puzzle.Puzzle, bool) {
+	var candidates []puzzle.Puzzle
+	for _, p := range allPuzzles {
+		if s.GetBest(p.ID).Stars < puzzle.OneStar && s.IsLevelUnlocked(p.Level, allPuzzles) {
+			candidates = append(candidates, p)
 		}
 	}
+	if len(candidates) == 0 {
+		return puzzle.Puzzle{}, false
+	}
+	return candidates[rand.IntN(len(candidates))], true
+}
+
+// Recommend suggests what to play next: the first unsolved puzzle in an
+// unlocked level, or else the first puzzle due for review. The reason is a
+// short explanation for display. It returns false when there is nothing to do.
+func (s *Store) Recommend(allPuzzles []puzzle.Puzzle) (puzzle.Puzzle, string, bool) {
+	for _, p := range allPuzzles {
+		if s.GetBest(p.ID).Stars < puzzle.OneStar && s.IsLevelUnlocked(p.Level, allPuzzles) {
+			return p, fmt.Sprintf("next unsolved puzzle in level %d", p.Level), true
+		}
+	}
+	if queue := s.ReviewQueue(allPuzzles); len(queue) > 0 {
+		p := queue[0]
+		r := s.GetBest(p.ID)
+		if r.Stars <= reviewStars {
+			return p, fmt.Sprintf("review: solved with %d star", r.Stars), true
+		}
+		return p, fmt.Sprintf("review: took %d attempts to reach %d stars", r.BestAttempts, r.Stars), true
+	}
+	return puzzle.Puzzle{}, "", false
+}
+
+// LastCompleted returns the ID and time of the most recently solved puzzle.
+// It returns an empty ID if no puzzle has a completion time recorded.
+func (s *Store) LastCompleted() (string, time.Time) {
+	var lastID string
+	var last time.Time
+	for id, r := range s.Results {
+		if r.CompletedAt.After(last) {
+			lastID = id
+			last = r.CompletedAt
+		}
+	}
+	return lastID, last
+}
+
+// solveDays returns the set of local calendar days with at least one solve,
+// from the solve history and best-result timestamps.
+func (s *Store) solveDays() map[time.Time]bool {
+	days := make(map[time.Time]bool)
+	add := func(t time.Time) {
+		if !t.IsZero() {
+			days[calendarDay(t)] = true
+		}
+	}
+	for _, r := range s.Results {
+		add(r.CompletedAt)
+	}
+	for _, h := range s.Solves {
+		for _, a := range h {
+			add(a.At)
+		}
+	}
+	return days
+}
+
+// calendarDay truncates t to midnight of its local calendar day.
+func calendarDay(t time.Time) time.Time {
+	y, m, d := t.In(time.Local).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+// CurrentStreak returns the number of consecutive days, ending today, with
+// at least one solve. A streak ending yesterday still counts until today
+// passes without a solve.
+func (s *Store) CurrentStreak() int {
+	days := s.solveDays()
+	day := calendarDay(now())
+	if !days[day] {
+		day = day.AddDate(0, 0, -1)
+	}
+	streak := 0
+	for days[day] {
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+	return streak
+}
+
+// LongestStreak returns the most consecutive days with at least one solve.
+func (s *Store) LongestStreak() int {
+	days := s.solveDays()
+	longest := 0
+	for day := range days {
+		if days[day.AddDate(0, 0, -1)] {
+			continue // not the start of a run
+		}
+		n := 0
+		for d := day; days[d]; d = d.AddDate(0, 0, 1) {
+			n++
+		}
+		longest = max(longest, n)
+	}
+	return longest
 }
 
 // IsLevelUnlocked checks if a level is unlocked.
 // Level 1 is always unlocked. Other levels require all puzzles in the previous level
-// to have at least 1 star.
+// to have at least 1 star, and the first level of a gated track also requires
+// its star gate total in the previous track.
 func (s *Store) IsLevelUnlocked(level int, allPuzzles []puzzle.Puzzle) bool {
 	if level <= 1 {
 		return true
 	}
+	if _, have, need, gated := s.TrackGate(level, allPuzzles); gated && have < need {
+		return false
+	}
 
 	// Find all puzzles in the previous level
 	prevLevel := level - 1
@@ -130,6 +601,23 @@ func (s *Store) IsLevelUnlocked(level int, allPuzzles []puzzle.Puzzle) bool {
 	return true
 }
 
+// IsMastered reports whether a puzzle has been solved with three stars.
+func (s *Store) IsMastered(puzzleID string) bool {
+	return s.GetBest(puzzleID).Stars >= puzzle.ThreeStar
+}
+
+// IsLevelMastered reports whether every puzzle in a level is mastered.
+// A level with no puzzles is not mastered.
+func (s *Store) IsLevelMastered(level int, allPuzzles []puzzle.Puzzle) bool {
+	puzzles := puzzle.GetPuzzlesForLevel(allPuzzles, level)
+	for _, p := range puzzles {
+		if !s.IsMastered(p.ID) {
+			return false
+		}
+	}
+	return len(puzzles) > 0
+}
+
 // GetLevelStars returns the minimum star rating across all puzzles in a level.
 func (s *Store) GetLevelStars(level int, allPuzzles []puzzle.Puzzle) puzzle.StarRating {
 	puzzles := puzzle.GetPuzzlesForLevel(allPuzzles, level)
@@ -164,6 +652,136 @@ func (s *Store) GetTrackStars(track int, allPuzzles []puzzle.Puzzle) puzzle.Star
 	return maxStars
 }
 
+// TrackLevelsSolved returns how many levels of a track have every puzzle
+// solved, and how many levels the track has.
+func (s *Store) TrackLevelsSolved(track int, allPuzzles []puzzle.Puzzle) (int, int) {
+	levels := puzzle.GetLevelsForTrack(allPuzzles, track)
+	solved := 0
+	for _, level := range levels {
+		if s.GetLevelStars(level, allPuzzles) >= puzzle.OneStar {
+			solved++
+		}
+	}
+	return solved, len(levels)
+}
+
+// SumTrackStars returns the total best stars earned across every puzzle in a track.
+func (s *Store) SumTrackStars(track int, allPuzzles []puzzle.Puzzle) int {
+	total := 0
+	for _, p := range allPuzzles {
+		if p.Track == track {
+			total += int(s.GetBest(p.ID).Stars)
+		}
+	}
+	return total
+}
+
+// SetTrackStarGates sets the stars that must be earned in the previous track
+// before each gated track's first level unlocks (Settings.TrackStarGates).
+func (s *Store) SetTrackStarGates(gates map[int]int) {
+	s.gates = gates
+}
+
+// TrackGate reports the star requirement on level, if it opens a gated track:
+// the previous track, the stars earned there, and the stars needed.
+func (s *Store) TrackGate(level int, allPuzzles []puzzle.Puzzle) (prevTrack, have, need int, gated bool) {
+	track := 0
+	for _, p := range allPuzzles {
+		if p.Level == level {
+			track = p.Track
+			break
+		}
+	}
+	need = s.gates[track]
+	if need <= 0 {
+		return 0, 0, 0, false
+	}
+	levels := puzzle.GetLevelsForTrack(allPuzzles, track)
+	if len(levels) == 0 || levels[0] != level {
+		return 0, 0, 0, false
+	}
+	for _, t := range puzzle.GetTracks(allPuzzles) {
+		if t < track {
+			prevTrack = t
+		}
+	}
+	if prevTrack == 0 {
+		return 0, 0, 0, false
+	}
+	return prevTrack, s.SumTrackStars(prevTrack, allPuzzles), need, true
+}
+
+// TotalKeystrokes returns the keystrokes of the best solve of every solved puzzle.
+func (s *Store) TotalKeystrokes(allPuzzles []puzzle.Puzzle) int {
+	total := 0
+	for _, p := range allPuzzles {
+		if r := s.GetBest(p.ID); r.Stars >= puzzle.OneStar {
+			total += r.Keystrokes
+		}
+	}
+	return total
+}
+
+// AverageStars returns the mean best star rating over solved puzzles, or 0
+// if none are solved.
+func (s *Store) AverageStars(allPuzzles []puzzle.Puzzle) float64 {
+	stars, solved := 0, 0
+	for _, p := range allPuzzles {
+		if r := s.GetBest(p.ID); r.Stars >= puzzle.OneStar {
+			stars += int(r.Stars)
+			solved++
+		}
+	}
+	if solved == 0 {
+		return 0
+	}
+	return float64(stars) / float64(solved)
+}
+
+// TrackProgress returns solved and total puzzle counts for a track.
+func (s *Store) TrackProgress(track int, allPuzzles []puzzle.Puzzle) (int, int) {
+	solved, total := 0, 0
+	for _, p := range allPuzzles {
+		if p.Track != track {
+			continue
+		}
+		total++
+		if s.GetBest(p.ID).Stars >= puzzle.OneStar {
+			solved++
+		}
+	}
+	return solved, total
+}
+
+// HardestUnsolvedLevel returns the level with unsolved puzzles whose puzzles
+// have the highest mean difficulty, preferring the later level on ties.
+func (s *Store) HardestUnsolvedLevel(allPuzzles []puzzle.Puzzle) (int, bool) {
+	type tally struct{ difficulty, count int }
+	levels := make(map[int]*tally)
+	unsolved := make(map[int]bool)
+	for _, p := range allPuzzles {
+		t := levels[p.Level]
+		if t == nil {
+			t = &tally{}
+			levels[p.Level] = t
+		}
+		t.difficulty += p.Difficulty
+		t.count++
+		if s.GetBest(p.ID).Stars < puzzle.OneStar {
+			unsolved[p.Level] = true
+		}
+	}
+
+	best, bestMean, found := 0, 0.0, false
+	for level := range unsolved {
+		mean := float64(levels[level].difficulty) / float64(levels[level].count)
+		if !found || mean > bestMean || mean == bestMean && level > best {
+			best, bestMean, found = level, mean, true
+		}
+	}
+	return best, found
+}
+
 // OverallProgress returns solved count, total puzzles, and percent solved.
 func (s *Store) OverallProgress(allPuzzles []puzzle.Puzzle) (int, int, int) {
 	total := len(allPuzzles)
@@ -0,0 +1,721 @@
+package progress
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vimgym/vimgym/internal/puzzle"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	return &Store{
+		dir:     t.TempDir(),
+		Results: make(map[string]PuzzleResult),
+		Solves:  make(map[string][]Attempt),
+	}
+}
+
+func TestRecordSolvePrunesOldest(t *testing.T) {
+	s := newTestStore(t)
+	s.HistoryLimit = 3
+	for i := 1; i <= 5; i++ {
+		s.RecordSolve("p1", Attempt{Keystrokes: i, Stars: puzzle.OneStar})
+	}
+
+	h := s.History("p1")
+	if len(h) != 3 {
+		t.Fatalf("len(History) = %d, want 3", len(h))
+	}
+	if h[0].Keystrokes != 3 || h[2].Keystrokes != 5 {
+		t.Errorf("History keystrokes = [%d..%d], want [3..5]", h[0].Keystrokes, h[2].Keystrokes)
+	}
+	if h[0].At.IsZero() {
+		t.Error("RecordSolve did not stamp the attempt time")
+	}
+}
+
+func TestLoadPrunesHistory(t *testing.T) {
+	s := newTestStore(t)
+	for i := 0; i < defaultHistoryLimit+5; i++ {
+		s.RecordSolve("p1", Attempt{Keystrokes: i})
+	}
+	s.HistoryLimit = 4
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := &Store{dir: s.dir}
+	if err := loaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(loaded.History("p1")); got != 4 {
+		t.Errorf("len(History) after Load = %d, want 4", got)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	puzzles := []puzzle.Puzzle{{ID: "p1"}, {ID: "p2"}, {ID: "p3"}, {ID: "p4"}}
+	a, b := newTestStore(t), newTestStore(t)
+	a.SetBest("p1", puzzle.ThreeStar, 5, nil)
+	b.SetBest("p1", puzzle.TwoStar, 4, nil)
+	a.SetBest("p2", puzzle.TwoStar, 9, nil)
+	b.SetBest("p2", puzzle.TwoStar, 8, nil)
+	a.SetBest("p3", puzzle.OneStar, 20, nil)
+	b.SetBest("p3", puzzle.OneStar, 20, nil)
+
+	c := Compare(a, b, puzzles)
+	if c.WinsA != 1 || c.WinsB != 1 || c.Ties != 1 {
+		t.Errorf("wins = %d/%d/%d, want 1/1/1", c.WinsA, c.WinsB, c.Ties)
+	}
+	if len(c.Puzzles) != 3 {
+		t.Errorf("len(Puzzles) = %d, want 3 (unsolved p4 skipped)", len(c.Puzzles))
+	}
+}
+
+func TestSetBestKeepsWinningReplay(t *testing.T) {
+	s := newTestStore(t)
+	if got := s.GetReplay("p1"); got != nil {
+		t.Fatalf("GetReplay before solve = %q, want nil", got)
+	}
+	s.SetBest("p1", puzzle.TwoStar, 6, []string{"l", "l", "l", "x"})
+	s.SetBest("p1", puzzle.ThreeStar, 3, []string{"3l", "x"})
+	s.SetBest("p1", puzzle.OneStar, 9, []string{"x"})
+
+	got := s.GetReplay("p1")
+	if len(got) != 2 || got[0] != "3l" || got[1] != "x" {
+		t.Errorf("GetReplay = %q, want [3l x]", got)
+	}
+}
+
+func TestTimestamps(t *testing.T) {
+	t1 := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	t2 := t1.Add(time.Hour)
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return t1 }
+
+	s := newTestStore(t)
+	if id, at := s.LastCompleted(); id != "" || !at.IsZero() {
+		t.Errorf("LastCompleted before any solve = %q, %v; want none", id, at)
+	}
+	s.SetBest("a", puzzle.TwoStar, 6, nil)
+	s.RecordSolve("a", Attempt{Keystrokes: 6})
+
+	now = func() time.Time { return t2 }
+	s.SetBest("b", puzzle.OneStar, 20, nil)
+	// A worse result keeps the time of the best one.
+	s.SetBest("a", puzzle.OneStar, 9, nil)
+	s.RecordSolve("a", Attempt{Keystrokes: 9})
+	s.RecordSolve("a", Attempt{Keystrokes: 8, At: t1.Add(time.Minute)})
+
+	if got := s.GetBest("a").CompletedAt; !got.Equal(t1) {
+		t.Errorf("CompletedAt of a = %v, want %v", got, t1)
+	}
+	if got := s.GetBest("b").CompletedAt; !got.Equal(t2) {
+		t.Errorf("CompletedAt of b = %v, want %v", got, t2)
+	}
+	if id, at := s.LastCompleted(); id != "b" || !at.Equal(t2) {
+		t.Errorf("LastCompleted = %q, %v; want b, %v", id, at, t2)
+	}
+	want := []time.Time{t1, t2, t1.Add(time.Minute)}
+	for i, a := range s.History("a") {
+		if i >= len(want) || !a.At.Equal(want[i]) {
+			t.Errorf("History(a)[%d].At = %v, want %v", i, a.At, want[min(i, len(want)-1)])
+		}
+	}
+	if got := len(s.History("a")); got != len(want) {
+		t.Errorf("len(History(a)) = %d, want %d", got, len(want))
+	}
+}
+
+func TestRandomUnsolved(t *testing.T) {
+	puzzles := []puzzle.Puzzle{
+		{ID: "a", Level: 1}, {ID: "b", Level: 1},
+		{ID: "c", Level: 2},
+	}
+	s := newTestStore(t)
+	s.SetBest("a", puzzle.TwoStar, 5, nil)
+
+	// Level 2 stays locked until b is solved, so b is the only candidate.
+	for range 10 {
+		p, ok := s.RandomUnsolved(puzzles)
+		if !ok || p.ID != "b" {
+			t.Fatalf("RandomUnsolved = %q, %v; want b, true", p.ID, ok)
+		}
+	}
+
+	s.SetBest("b", puzzle.OneStar, 9, nil)
+	if p, ok := s.RandomUnsolved(puzzles); !ok || p.ID != "c" {
+		t.Errorf("RandomUnsolved = %q, %v; want c, true", p.ID, ok)
+	}
+
+	s.SetBest("c", puzzle.ThreeStar, 2, nil)
+	if p, ok := s.RandomUnsolved(puzzles); ok {
+		t.Errorf("RandomUnsolved = %q, true; want false when all solved", p.ID)
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	puzzles := []puzzle.Puzzle{{ID: "a", Level: 1}, {ID: "b", Level: 1}}
+	s := newTestStore(t)
+
+	if p, _, ok := s.Recommend(puzzles); !ok || p.ID != "a" {
+		t.Errorf("Recommend = %q, %v; want first unsolved a", p.ID, ok)
+	}
+
+	s.SetBest("a", puzzle.OneStar, 9, nil)
+	s.SetBest("b", puzzle.ThreeStar, 2, nil)
+	p, reason, ok := s.Recommend(puzzles)
+	if !ok || p.ID != "a" || !strings.Contains(reason, "review") {
+		t.Errorf("Recommend = %q, %q, %v; want review of a", p.ID, reason, ok)
+	}
+
+	// The reason names the attempts the review rule counted: those it took
+	// to reach the best, not every attempt since.
+	for range 5 {
+		s.RecordAttempt("a")
+	}
+	s.SetBest("a", puzzle.TwoStar, 6, nil)
+	s.RecordAttempt("a")
+	if _, reason, _ := s.Recommend(puzzles); reason != "review: took 5 attempts to reach 2 stars" {
+		t.Errorf("Recommend reason = %q, want the 5 attempts to reach the best", reason)
+	}
+
+	s.SetBest("a", puzzle.ThreeStar, 3, nil)
+	if p, _, ok := s.Recommend(puzzles); ok {
+		t.Errorf("Recommend = %q, true; want nothing left", p.ID)
+	}
+}
+
+func TestTrackStarGates(t *testing.T) {
+	puzzles := []puzzle.Puzzle{
+		{ID: "a", Track: 1, Level: 1},
+		{ID: "b", Track: 1, Level: 2},
+		{ID: "c", Track: 2, Level: 3},
+		{ID: "d", Track: 2, Level: 4},
+	}
+	s := newTestStore(t)
+	s.SetBest("a", puzzle.ThreeStar, 1, nil)
+	s.SetBest("b", puzzle.OneStar, 9, nil)
+
+	if !s.IsLevelUnlocked(3, puzzles) {
+		t.Fatal("level 3 locked without a track gate")
+	}
+
+	s.SetTrackStarGates(map[int]int{2: 5})
+	if got := s.SumTrackStars(1, puzzles); got != 4 {
+		t.Errorf("SumTrackStars(1) = %d, want 4", got)
+	}
+	prev, have, need, gated := s.TrackGate(3, puzzles)
+	if !gated || prev != 1 || have != 4 || need != 5 {
+		t.Errorf("TrackGate(3) = %d, %d, %d, %v; want 1, 4, 5, true", prev, have, need, gated)
+	}
+	if s.IsLevelUnlocked(3, puzzles) {
+		t.Error("level 3 unlocked with 4 of 5 gate stars")
+	}
+	if _, _, _, gated := s.TrackGate(4, puzzles); gated {
+		t.Error("only the first level of a track should be gated")
+	}
+
+	s.SetBest("b", puzzle.TwoStar, 5, nil)
+	if !s.IsLevelUnlocked(3, puzzles) {
+		t.Error("level 3 still locked after meeting the gate")
+	}
+}
+
+func TestIsMastered(t *testing.T) {
+	puzzles := []puzzle.Puzzle{{ID: "a", Level: 1}, {ID: "b", Level: 1}}
+	s := newTestStore(t)
+	s.SetBest("a", puzzle.ThreeStar, 1, nil)
+	s.SetBest("b", puzzle.OneStar, 9, nil)
+
+	if !s.IsMastered("a") || s.IsMastered("b") {
+		t.Error("IsMastered should hold only for three-star solves")
+	}
+	if s.IsLevelMastered(1, puzzles) {
+		t.Error("level mastered with a one-star puzzle")
+	}
+	s.SetBest("b", puzzle.ThreeStar, 2, nil)
+	if !s.IsLevelMastered(1, puzzles) {
+		t.Error("level not mastered with every puzzle at three stars")
+	}
+	if s.IsLevelMastered(2, puzzles) {
+		t.Error("empty level reported as mastered")
+	}
+}
+
+func TestTrackLevelsSolved(t *testing.T) {
+	puzzles := []puzzle.Puzzle{
+		{ID: "a", Track: 1, Level: 1},
+		{ID: "b", Track: 1, Level: 2},
+		{ID: "c", Track: 1, Level: 2},
+		{ID: "d", Track: 2, Level: 3},
+	}
+	s := newTestStore(t)
+	s.SetBest("a", puzzle.TwoStar, 4, nil)
+	s.SetBest("b", puzzle.ThreeStar, 2, nil)
+
+	if solved, total := s.TrackLevelsSolved(1, puzzles); solved != 1 || total != 2 {
+		t.Errorf("TrackLevelsSolved(1) = %d/%d, want 1/2", solved, total)
+	}
+	s.SetBest("c", puzzle.OneStar, 9, nil)
+	if solved, total := s.TrackLevelsSolved(1, puzzles); solved != 2 || total != 2 {
+		t.Errorf("TrackLevelsSolved(1) = %d/%d, want 2/2", solved, total)
+	}
+}
+
+func TestStatsAggregates(t *testing.T) {
+	puzzles := []puzzle.Puzzle{
+		{ID: "a", Track: 1, Level: 1, Difficulty: 1},
+		{ID: "b", Track: 1, Level: 1, Difficulty: 1},
+		{ID: "c", Track: 2, Level: 2, Difficulty: 3},
+		{ID: "d", Track: 2, Level: 3, Difficulty: 2},
+	}
+	s := newTestStore(t)
+	if got := s.AverageStars(puzzles); got != 0 {
+		t.Errorf("AverageStars with nothing solved = %v, want 0", got)
+	}
+
+	s.SetBest("a", puzzle.ThreeStar, 4, nil)
+	s.SetBest("b", puzzle.TwoStar, 7, nil)
+	s.SetBest("d", puzzle.OneStar, 20, nil)
+
+	if got := s.TotalKeystrokes(puzzles); got != 31 {
+		t.Errorf("TotalKeystrokes = %d, want 31", got)
+	}
+	if got := s.AverageStars(puzzles); got != 2 {
+		t.Errorf("AverageStars = %v, want 2", got)
+	}
+	if solved, total := s.TrackProgress(2, puzzles); solved != 1 || total != 2 {
+		t.Errorf("TrackProgress(2) = %d/%d, want 1/2", solved, total)
+	}
+	if level, ok := s.HardestUnsolvedLevel(puzzles); !ok || level != 2 {
+		t.Errorf("HardestUnsolvedLevel = %d, %v; want 2, true", level, ok)
+	}
+
+	s.SetBest("c", puzzle.OneStar, 30, nil)
+	if level, ok := s.HardestUnsolvedLevel(puzzles); ok {
+		t.Errorf("HardestUnsolvedLevel = %d, true; want none once all are solved", level)
+	}
+}
+
+func TestStreaks(t *testing.T) {
+	today := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return today }
+
+	s := newTestStore(t)
+	if s.CurrentStreak() != 0 || s.LongestStreak() != 0 {
+		t.Error("streaks should be zero before any solve")
+	}
+
+	solveOn := func(id string, daysAgo, hour int) {
+		at := time.Date(2026, 3, 10-daysAgo, hour, 0, 0, 0, time.Local)
+		s.Solves[id] = append(s.Solves[id], Attempt{At: at})
+	}
+	// A four-day run a week ago, then yesterday and the day before.
+	for d := 7; d <= 10; d++ {
+		solveOn("old", d, 12)
+	}
+	solveOn("a", 2, 23)
+	solveOn("b", 1, 0)
+
+	if got := s.CurrentStreak(); got != 2 {
+		t.Errorf("CurrentStreak = %d, want 2 (still alive from yesterday)", got)
+	}
+	if got := s.LongestStreak(); got != 4 {
+		t.Errorf("LongestStreak = %d, want 4", got)
+	}
+
+	solveOn("a", 0, 8)
+	if got := s.CurrentStreak(); got != 3 {
+		t.Errorf("CurrentStreak after solving today = %d, want 3", got)
+	}
+
+	// Two days without a solve breaks the streak.
+	now = func() time.Time { return today.AddDate(0, 0, 2) }
+	if got := s.CurrentStreak(); got != 0 {
+		t.Errorf("CurrentStreak after a missed day = %d, want 0", got)
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	s := newTestStore(t)
+	s.SetBest("a", puzzle.ThreeStar, 4, []string{"d", "w"})
+	s.SetBest("b", puzzle.OneStar, 20, nil)
+	s.RecordSolve("a", Attempt{Keystrokes: 4, Par: 4, Stars: puzzle.ThreeStar})
+
+	dir := t.TempDir()
+	if err := s.Export(filepath.Join(dir, progressFile), FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range s.Results {
+		got := loaded.Results[id]
+		if got.Stars != want.Stars || got.Keystrokes != want.Keystrokes || !got.CompletedAt.Equal(want.CompletedAt) {
+			t.Errorf("%s: loaded %+v, exported %+v", id, got, want)
+		}
+	}
+	if len(loaded.Solves["a"]) != 1 {
+		t.Errorf("history not round-tripped: %v", loaded.Solves)
+	}
+
+	csvPath := filepath.Join(dir, "progress.csv")
+	if err := s.Export(csvPath, FormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 || lines[0] != "puzzleID,stars,keystrokes,timestamp" || !strings.HasPrefix(lines[1], "a,3,4,") {
+		t.Errorf("unexpected CSV export:\n%s", data)
+	}
+
+	if err := s.Export(filepath.Join(dir, "x"), "xml"); err == nil {
+		t.Error("unknown format was accepted")
+	}
+}
+
+func TestImportKeepsBest(t *testing.T) {
+	other := newTestStore(t)
+	other.SetBest("better", puzzle.ThreeStar, 5, []string{"x"})
+	other.SetBest("worse", puzzle.OneStar, 30, nil)
+	other.SetBest("tie", puzzle.TwoStar, 8, nil)
+	other.SetBest("new", puzzle.TwoStar, 12, nil)
+	other.RecordSolve("new", Attempt{Keystrokes: 12, Par: 10, Stars: puzzle.TwoStar})
+	if err := other.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newTestStore(t)
+	s.SetBest("better", puzzle.TwoStar, 9, nil)
+	s.SetBest("worse", puzzle.ThreeStar, 6, nil)
+	s.SetBest("tie", puzzle.TwoStar, 10, nil)
+	if err := s.Import(filepath.Join(other.dir, progressFile)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id         string
+		stars      puzzle.StarRating
+		keystrokes int
+	}{
+		{"better", puzzle.ThreeStar, 5},
+		{"worse", puzzle.ThreeStar, 6},
+		{"tie", puzzle.TwoStar, 8},
+		{"new", puzzle.TwoStar, 12},
+	}
+	for _, tt := range tests {
+		got := s.GetBest(tt.id)
+		if got.Stars != tt.stars || got.Keystrokes != tt.keystrokes {
+			t.Errorf("%s: got %d stars in %d keys, want %d in %d", tt.id, got.Stars, got.Keystrokes, tt.stars, tt.keystrokes)
+		}
+	}
+	if replay := s.GetReplay("better"); len(replay) != 1 || replay[0] != "x" {
+		t.Errorf("winning replay not imported: %v", replay)
+	}
+	if len(s.History("new")) != 1 {
+		t.Errorf("history of a new puzzle not imported: %v", s.History("new"))
+	}
+
+	// Importing the same file again changes nothing, and the merge was saved.
+	if err := s.Import(filepath.Join(other.dir, progressFile)); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := Open(s.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := saved.GetBest("better"); got.Stars != puzzle.ThreeStar || len(saved.History("new")) != 1 {
+		t.Errorf("merge not persisted or duplicated history: %+v %v", got, saved.History("new"))
+	}
+}
+
+func TestImportRebasesAttemptsAtBest(t *testing.T) {
+	attempts := func(s *Store, id string, n int) {
+		for range n {
+			s.RecordAttempt(id)
+		}
+	}
+	// They reached two stars on their 5th attempt and tried once more.
+	other := newTestStore(t)
+	attempts(other, "p", 5)
+	other.SetBest("p", puzzle.TwoStar, 9, nil)
+	attempts(other, "p", 1)
+	if err := other.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// We have one star from our 2nd attempt.
+	s := newTestStore(t)
+	attempts(s, "p", 2)
+	s.SetBest("p", puzzle.OneStar, 20, nil)
+	if err := s.Import(filepath.Join(other.dir, progressFile)); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.GetBest("p"); got.Attempts != 6 || got.AttemptsAtBest != 5 {
+		t.Fatalf("attempts %d, at best %d; want 6 and 5", got.Attempts, got.AttemptsAtBest)
+	}
+
+	// Improving on the next attempt took two attempts since their best.
+	attempts(s, "p", 1)
+	s.SetBest("p", puzzle.TwoStar, 8, nil)
+	if got := s.GetBest("p"); got.BestAttempts != 2 || got.NeedsReview {
+		t.Errorf("best attempts %d, needs review %v; want 2 and no review", got.BestAttempts, got.NeedsReview)
+	}
+}
+
+func TestSaveIsAtomic(t *testing.T) {
+	s := newTestStore(t)
+	s.SetBest("a", puzzle.ThreeStar, 4, nil)
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(s.dir, progressFile)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0644 {
+		t.Errorf("progress file mode = %o, want 644", perm)
+	}
+	if tmps, _ := filepath.Glob(path + ".tmp-*"); len(tmps) != 0 {
+		t.Errorf("temp files left behind: %v", tmps)
+	}
+
+	// A save interrupted after writing part of its temp file leaves the
+	// previous progress untouched.
+	if err := os.WriteFile(path+".tmp-crash", []byte(`{"results": {"a": {"sta`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Open(s.dir)
+	if err != nil {
+		t.Fatalf("Load after interrupted save: %v", err)
+	}
+	if got := loaded.GetBest("a"); got.Stars != puzzle.ThreeStar || got.Keystrokes != 4 {
+		t.Errorf("loaded %+v, want the last good save", got)
+	}
+}
+
+func TestLoadMigratesVersion0(t *testing.T) {
+	solvedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	v0 := `{
+  "results": {
+    "a": {"stars": 3, "keystrokes": 4},
+    "b": {"stars": 1, "keystrokes": 30},
+    "c": {"stars": 0, "keystrokes": 0, "attempts": 2}
+  },
+  "history": {
+    "a": [
+      {"at": "2024-02-01T10:00:00Z", "keystrokes": 9, "par": 4, "stars": 1},
+      {"at": "` + solvedAt.Format(time.RFC3339) + `", "keystrokes": 4, "par": 4, "stars": 3}
+    ]
+  }
+}`
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, progressFile), []byte(v0), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Version != schemaVersion {
+		t.Errorf("version = %d, want %d", s.Version, schemaVersion)
+	}
+	a := s.GetBest("a")
+	if a.Stars != puzzle.ThreeStar || a.Keystrokes != 4 || !a.CompletedAt.Equal(solvedAt) || a.Attempts != 1 {
+		t.Errorf("a migrated to %+v", a)
+	}
+	if b := s.GetBest("b"); b.Keystrokes != 30 || !b.CompletedAt.IsZero() || !b.NeedsReview {
+		t.Errorf("b migrated to %+v", b)
+	}
+	if c := s.GetBest("c"); c.Attempts != 2 || c.NeedsReview {
+		t.Errorf("c migrated to %+v", c)
+	}
+	if len(s.History("a")) != 2 {
+		t.Errorf("history lost in migration: %v", s.History("a"))
+	}
+
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, progressFile))
+	if !strings.Contains(string(data), fmt.Sprintf(`"version": %d`, schemaVersion)) {
+		t.Errorf("saved file lacks the schema version:\n%s", data)
+	}
+}
+
+func TestLoadRecoversCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(DataDirEnv, dir)
+	path := filepath.Join(dir, progressFile)
+	if err := os.WriteFile(path, []byte(`{"results": {"a": {"sta`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := New()
+	if err != nil {
+		t.Fatalf("New with a corrupt file: %v", err)
+	}
+	var corrupt *CorruptFileError
+	if !errors.As(s.Warning(), &corrupt) {
+		t.Fatalf("Warning() = %v, want a *CorruptFileError", s.Warning())
+	}
+	if len(s.Results) != 0 || s.Solves == nil {
+		t.Errorf("store not reset: %+v", s)
+	}
+	if !strings.HasPrefix(filepath.Base(corrupt.Backup), progressFile+".corrupt-") {
+		t.Errorf("unexpected backup name %s", corrupt.Backup)
+	}
+	if data, err := os.ReadFile(corrupt.Backup); err != nil || !strings.Contains(string(data), `"sta`) {
+		t.Errorf("backup missing or altered: %q, %v", data, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("corrupt file left in place: %v", err)
+	}
+
+	// The fresh store saves normally.
+	s.SetBest("a", puzzle.TwoStar, 9, nil)
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(dir); err != nil {
+		t.Errorf("reloading after recovery: %v", err)
+	}
+}
+
+func TestOpenLeavesCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, progressFile)
+	data := []byte(`{"results": {"a": {"sta`)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(dir); err == nil {
+		t.Fatal("Open of a corrupt file succeeded")
+	}
+	if got, err := os.ReadFile(path); err != nil || string(got) != string(data) {
+		t.Errorf("corrupt file moved or altered: %q, %v", got, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Open wrote to the directory: %v", entries)
+	}
+}
+
+func TestFavorites(t *testing.T) {
+	s := newTestStore(t)
+	all := []puzzle.Puzzle{{ID: "a", Level: 1}, {ID: "b", Level: 2}, {ID: "c", Level: 3}}
+	if s.IsFavorite("a") || len(s.FavoritePuzzles(all)) != 0 {
+		t.Fatal("new store has favorites")
+	}
+	s.ToggleFavorite("c")
+	s.ToggleFavorite("a")
+	s.ToggleFavorite("b")
+	s.ToggleFavorite("b")
+	if got := s.FavoritePuzzles(all); len(got) != 2 || got[0].ID != "a" || got[1].ID != "c" {
+		t.Errorf("FavoritePuzzles = %v, want a and c", got)
+	}
+
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Open(s.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.IsFavorite("a") || loaded.IsFavorite("b") {
+		t.Errorf("favorites not persisted: %v", loaded.Favorites)
+	}
+}
+
+func TestReviewQueue(t *testing.T) {
+	puzzles := []puzzle.Puzzle{{ID: "a", Level: 1}, {ID: "b", Level: 1}, {ID: "c", Level: 1}, {ID: "d", Level: 1}}
+	s := newTestStore(t)
+	attempt := func(id string, n int) {
+		for range n {
+			s.RecordAttempt(id)
+		}
+	}
+	ids := func() []string {
+		var ids []string
+		for _, p := range s.ReviewQueue(puzzles) {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+
+	// Opening a puzzle many times without solving it flags nothing.
+	attempt("a", reviewAttempts+2)
+	if got := ids(); len(got) != 0 {
+		t.Fatalf("unsolved puzzles queued: %v", got)
+	}
+
+	// A one-star best is weak however quickly it came.
+	attempt("b", 1)
+	s.SetBest("b", puzzle.OneStar, 20, nil)
+	// Two stars are fine in a few attempts, but not after many.
+	attempt("c", 2)
+	s.SetBest("c", puzzle.TwoStar, 6, nil)
+	s.SetBest("a", puzzle.TwoStar, 6, nil) // after all those attempts
+	// Three stars never need review, even after many attempts.
+	attempt("d", reviewAttempts+2)
+	s.SetBest("d", puzzle.ThreeStar, 4, nil)
+	if got, want := ids(), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ReviewQueue = %v, want %v", got, want)
+	}
+
+	// Retrying a queued puzzle up to three stars clears it.
+	attempt("a", 1)
+	s.SetBest("a", puzzle.ThreeStar, 4, nil)
+	// Attempts count from the last best: a quick improvement is not weak.
+	attempt("b", 1)
+	s.SetBest("b", puzzle.TwoStar, 6, nil)
+	if got := ids(); len(got) != 0 {
+		t.Fatalf("ReviewQueue after improving = %v, want empty", got)
+	}
+
+	// Struggling to improve again queues it once the better best lands.
+	attempt("c", reviewAttempts)
+	s.SetBest("c", puzzle.TwoStar, 5, nil)
+	if got, want := ids(), []string{"c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReviewQueue = %v, want %v", got, want)
+	}
+}
+
+func TestLoadRecomputesReviewFlags(t *testing.T) {
+	v1 := `{
+  "version": 1,
+  "results": {
+    "a": {"stars": 2, "keystrokes": 6, "attempts": 9, "needsReview": true},
+    "b": {"stars": 1, "keystrokes": 30, "attempts": 1, "needsReview": true}
+  }
+}`
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, progressFile), []byte(v1), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.GetBest("a").NeedsReview {
+		t.Error("a two-star result stayed flagged for its load count alone")
+	}
+	if !s.GetBest("b").NeedsReview {
+		t.Error("a one-star result lost its review flag")
+	}
+}
@@ -0,0 +1,48 @@
+package puzzle
+
+import "strings"
+
+// namedKeys are the special key names recognized inside <...> in solutions.
+var namedKeys = map[string]bool{
+	"esc": true, "cr": true, "enter": true, "tab": true, "bs": true,
+	"del": true, "space": true, "lt": true, "up": true, "down": true,
+	"left": true, "right": true, "home": true, "end": true,
+	"pageup": true, "pagedown": true,
+}
+
+// SplitKeys splits a key sequence in Vim notation (e.g. "ci\"x<Esc>") into
+// individual keystrokes. Special keys like <Esc> or <C-v> count as one key;
+// other text in angle brackets (e.g. "<div>") is treated literally.
+func SplitKeys(seq string) []string {
+	var keys []string
+	for i := 0; i < len(seq); {
+		if seq[i] == '<' {
+			if end := strings.IndexByte(seq[i:], '>'); end > 1 && isKeyNotation(seq[i+1:i+end]) {
+				keys = append(keys, seq[i:i+end+1])
+				i += end + 1
+				continue
+			}
+		}
+		r := []rune(seq[i:])[0]
+		keys = append(keys, string(r))
+		i += len(string(r))
+	}
+	return keys
+}
+
+// KeyCount returns the number of keystrokes in a key sequence.
+func KeyCount(seq string) int {
+	return len(SplitKeys(seq))
+}
+
+func isKeyNotation(name string) bool {
+	if namedKeys[strings.ToLower(name)] {
+		return true
+	}
+	// Modifier combos like C-v, M-x, S-Tab.
+	if len(name) >= 3 && name[1] == '-' && strings.ContainsRune("CMSA", rune(name[0])) {
+		rest := name[2:]
+		return len([]rune(rest)) == 1 || namedKeys[strings.ToLower(rest)]
+	}
+	return false
+}
@@ -2,10 +2,13 @@ package puzzle
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
+	"path/filepath"
 	"sort"
+	"strings"
 )
 
 // LoadFromFile loads puzzles from a JSON file on disk.
@@ -18,6 +21,12 @@ func LoadFromFile(path string) ([]Puzzle, error) {
 	if err := json.Unmarshal(data, &puzzles); err != nil {
 		return nil, fmt.Errorf("parsing puzzle file: %w", err)
 	}
+	for i := range puzzles {
+		normalizeText(&puzzles[i])
+	}
+	if err := validateDefinitions(puzzles, filepath.Base(path)); err != nil {
+		return nil, fmt.Errorf("invalid puzzles:\n%w", err)
+	}
 	return puzzles, nil
 }
 
@@ -29,6 +38,8 @@ func LoadFromFS(fsys fs.FS, dir string) ([]Puzzle, error) {
 	}
 
 	var all []Puzzle
+	var invalid []error
+	sources := make(map[string][]string) // puzzle ID -> files declaring it
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
@@ -45,17 +56,136 @@ func LoadFromFS(fsys fs.FS, dir string) ([]Puzzle, error) {
 		if err := json.Unmarshal(data, &puzzles); err != nil {
 			return nil, fmt.Errorf("parsing file %s: %w", entry.Name(), err)
 		}
+		for i := range puzzles {
+			normalizeText(&puzzles[i])
+		}
+		if err := validateDefinitions(puzzles, entry.Name()); err != nil {
+			invalid = append(invalid, err)
+		}
+		for _, p := range puzzles {
+			sources[p.ID] = append(sources[p.ID], entry.Name())
+		}
 		all = append(all, puzzles...)
 	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("invalid puzzles:\n%w", errors.Join(invalid...))
+	}
+	if err := duplicateIDs(sources); err != nil {
+		return nil, err
+	}
 
-	sort.Slice(all, func(i, j int) bool {
-		if all[i].Track != all[j].Track {
-			return all[i].Track < all[j].Track
+	sortPuzzles(all)
+	return all, nil
+}
+
+// duplicateIDs returns an error naming every puzzle ID declared more than
+// once, along with the files that declare it.
+func duplicateIDs(sources map[string][]string) error {
+	var dups []string
+	for id, files := range sources {
+		if len(files) > 1 {
+			dups = append(dups, fmt.Sprintf("%s (in %s)", id, strings.Join(files, ", ")))
+		}
+	}
+	if len(dups) == 0 {
+		return nil
+	}
+	sort.Strings(dups)
+	return fmt.Errorf("duplicate puzzle IDs: %s", strings.Join(dups, "; "))
+}
+
+// sortPuzzles orders puzzles by track and level, keeping file order within a level.
+func sortPuzzles(puzzles []Puzzle) {
+	sort.SliceStable(puzzles, func(i, j int) bool {
+		if puzzles[i].Track != puzzles[j].Track {
+			return puzzles[i].Track < puzzles[j].Track
 		}
-		return all[i].Level < all[j].Level
+		return puzzles[i].Level < puzzles[j].Level
 	})
+}
 
-	return all, nil
+// dataDirEnv overrides the data directory, as progress.DataDirEnv does;
+// progress imports this package, so the name is repeated here.
+const dataDirEnv = "VIMGYM_DATA_DIR"
+
+// UserPuzzleDir returns the directory for user-supplied puzzle packs: the
+// puzzles directory in the data directory, $VIMGYM_DATA_DIR when set and
+// ~/.vimgym otherwise.
+func UserPuzzleDir() (string, error) {
+	if dir := os.Getenv(dataDirEnv); dir != "" {
+		return filepath.Join(dir, "puzzles"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home dir: %w", err)
+	}
+	return filepath.Join(home, ".vimgym", "puzzles"), nil
+}
+
+// LoadFromDir loads every *.json puzzle file in dir. A file that fails to load
+// is reported in the returned warnings without stopping the others.
+func LoadFromDir(dir string) ([]Puzzle, []error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, []error{fmt.Errorf("listing %s: %w", dir, err)}
+	}
+	sort.Strings(paths)
+
+	var all []Puzzle
+	var warnings []error
+	for _, path := range paths {
+		puzzles, err := LoadFromFile(path)
+		if err != nil {
+			warnings = append(warnings, fmt.Errorf("%s: %w", filepath.Base(path), err))
+			continue
+		}
+		all = append(all, puzzles...)
+	}
+	return all, warnings
+}
+
+// LoadUserPuzzles merges user puzzle packs from UserPuzzleDir into builtin.
+// User puzzles replace built-in puzzles with the same ID. A missing directory
+// is not an error; per-file problems are returned as warnings.
+func LoadUserPuzzles(builtin []Puzzle) ([]Puzzle, []error) {
+	dir, err := UserPuzzleDir()
+	if err != nil {
+		return builtin, []error{err}
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return builtin, nil
+	}
+	user, warnings := LoadFromDir(dir)
+	return MergePuzzles(builtin, user), warnings
+}
+
+// MergePuzzles combines two puzzle sets; puzzles in overrides replace those in
+// base with the same ID. The result is sorted by track and level.
+func MergePuzzles(base, overrides []Puzzle) []Puzzle {
+	index := make(map[string]int, len(base))
+	merged := make([]Puzzle, 0, len(base)+len(overrides))
+	for _, p := range base {
+		index[p.ID] = len(merged)
+		merged = append(merged, p)
+	}
+	for _, p := range overrides {
+		if i, ok := index[p.ID]; ok {
+			merged[i] = p
+			continue
+		}
+		index[p.ID] = len(merged)
+		merged = append(merged, p)
+	}
+	sortPuzzles(merged)
+	return merged
+}
+
+// SortByDifficulty orders puzzles from easiest to hardest in place, keeping
+// the existing order among puzzles of equal difficulty.
+func SortByDifficulty(puzzles []Puzzle) {
+	sort.SliceStable(puzzles, func(i, j int) bool {
+		return puzzles[i].Difficulty < puzzles[j].Difficulty
+	})
 }
 
 // GroupByLevel groups puzzles by their level number.
@@ -107,3 +237,45 @@ func GetPuzzlesForLevel(puzzles []Puzzle, level int) []Puzzle {
 	}
 	return result
 }
+
+// FindByID returns the puzzle with the given ID.
+func FindByID(puzzles []Puzzle, id string) (Puzzle, bool) {
+	for _, p := range puzzles {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return Puzzle{}, false
+}
+
+// FilterByTag returns puzzles that have the given tag (case-insensitive).
+func FilterByTag(puzzles []Puzzle, tag string) []Puzzle {
+	var result []Puzzle
+	for _, p := range puzzles {
+		for _, t := range p.Tags {
+			if strings.EqualFold(t, tag) {
+				result = append(result, p)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// AllTags returns the sorted, deduplicated set of non-empty tags.
+func AllTags(puzzles []Puzzle) []string {
+	seen := make(map[string]bool)
+	for _, p := range puzzles {
+		for _, t := range p.Tags {
+			if strings.TrimSpace(t) != "" {
+				seen[t] = true
+			}
+		}
+	}
+	tags := make([]string, 0, len(seen))
+	for t := range seen {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+	return tags
+}
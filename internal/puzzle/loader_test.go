@@ -1,7 +1,11 @@
 package puzzle
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 func TestValidate(t *testing.T) {
@@ -27,6 +31,30 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestValidatePrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  string
+		target   string
+		expected bool
+	}{
+		{"empty buffer", "", "hello", true},
+		{"partial", "hel", "hello", true},
+		{"complete", "hello", "hello", true},
+		{"typo", "hex", "hello", false},
+		{"too long", "hello!", "hello", false},
+		{"multi-line partial", "line1\nli", "line1\nline2", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidatePrefix(tt.current, tt.target); got != tt.expected {
+				t.Errorf("ValidatePrefix(%q, %q) = %v, want %v", tt.current, tt.target, got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestScore(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -50,3 +78,243 @@ func TestScore(t *testing.T) {
 		})
 	}
 }
+
+func TestPuzzleScoreTwoStarPar(t *testing.T) {
+	derived := Puzzle{Par: 10}
+	if got := derived.TwoStarCutoff(); got != 15 {
+		t.Errorf("derived TwoStarCutoff = %d, want 15", got)
+	}
+	if got := derived.Score(15); got != TwoStar {
+		t.Errorf("derived Score(15) = %v, want TwoStar", got)
+	}
+	if got := derived.Score(16); got != OneStar {
+		t.Errorf("derived Score(16) = %v, want OneStar", got)
+	}
+
+	custom := Puzzle{Par: 10, TwoStarPar: 12}
+	if got := custom.Score(10); got != ThreeStar {
+		t.Errorf("custom Score(10) = %v, want ThreeStar", got)
+	}
+	if got := custom.Score(12); got != TwoStar {
+		t.Errorf("custom Score(12) = %v, want TwoStar", got)
+	}
+	if got := custom.Score(13); got != OneStar {
+		t.Errorf("custom Score(13) = %v, want OneStar", got)
+	}
+}
+
+func TestScoreAssisted(t *testing.T) {
+	p := Puzzle{Par: 8}
+	tests := []struct {
+		name       string
+		keystrokes int
+		hint       bool
+		solution   bool
+		want       StarRating
+	}{
+		{"unassisted", 8, false, false, ThreeStar},
+		{"hint pushes over par", 8, true, false, TwoStar},
+		{"hint with keys to spare", 6, true, false, ThreeStar},
+		{"solution caps at two", 5, false, true, TwoStar},
+		{"solution keeps one star", 20, false, true, OneStar},
+		{"hint and solution", 10, true, true, TwoStar},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.ScoreAssisted(tt.keystrokes, tt.hint, tt.solution); got != tt.want {
+				t.Errorf("ScoreAssisted(%d, %v, %v) = %v, want %v", tt.keystrokes, tt.hint, tt.solution, got, tt.want)
+			}
+		})
+	}
+	if got := (Puzzle{Par: 3}).HintPenalty(); got != 1 {
+		t.Errorf("HintPenalty with par 3 = %d, want 1", got)
+	}
+}
+
+func TestScoreGolf(t *testing.T) {
+	tests := []struct {
+		name       string
+		keystrokes int
+		want       StarRating
+	}{
+		{"exact optimal", 12, ThreeStar},
+		{"under optimal", 10, ThreeStar},
+		{"one over", 13, TwoStar},
+		{"far over", 30, OneStar},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScoreGolf(tt.keystrokes, 12); got != tt.want {
+				t.Errorf("ScoreGolf(%d, 12) = %v, want %v", tt.keystrokes, got, tt.want)
+			}
+		})
+	}
+
+	golf := Puzzle{Track: 4, Par: 20, OptimalSolution: "dd"}
+	if got := golf.Score(4); got != OneStar {
+		t.Errorf("golf track Score(4) = %v, want OneStar (optimal is 2 keys)", got)
+	}
+	golf.ScoreMode = ScoreModePar
+	if got := golf.Score(4); got != ThreeStar {
+		t.Errorf("par mode override Score(4) = %v, want ThreeStar", got)
+	}
+}
+
+func TestIsPerfect(t *testing.T) {
+	p := Puzzle{Par: 5, OptimalSolution: "d2w<Esc>"}
+	if !p.IsPerfect(4) || !p.IsPerfect(3) {
+		t.Error("solve at or under the optimal length should be perfect")
+	}
+	if p.IsPerfect(5) {
+		t.Error("solve over the optimal length should not be perfect")
+	}
+	if (Puzzle{Par: 5}).IsPerfect(1) {
+		t.Error("puzzle without an optimal solution can't be perfect")
+	}
+}
+
+func TestSplitKeys(t *testing.T) {
+	tests := []struct {
+		seq      string
+		expected []string
+	}{
+		{"dw", []string{"d", "w"}},
+		{"ci\"x<Esc>", []string{"c", "i", "\"", "x", "<Esc>"}},
+		{"<C-v>jjIa<Esc>", []string{"<C-v>", "j", "j", "I", "a", "<Esc>"}},
+		{"I<li><Esc>", []string{"I", "<", "l", "i", ">", "<Esc>"}},
+		{":s/a/b<CR>", []string{":", "s", "/", "a", "/", "b", "<CR>"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.seq, func(t *testing.T) {
+			got := SplitKeys(tt.seq)
+			if strings.Join(got, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("SplitKeys(%q) = %q, want %q", tt.seq, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilterByTag(t *testing.T) {
+	puzzles := []Puzzle{
+		{ID: "a", Tags: []string{"ciw", "text-object"}},
+		{ID: "b", Tags: []string{"Text-Object", "dd"}},
+		{ID: "c", Tags: nil},
+		{ID: "d", Tags: []string{""}},
+	}
+
+	got := FilterByTag(puzzles, "text-object")
+	if len(got) != 2 || got[0].ID != "a" || got[1].ID != "b" {
+		t.Errorf("FilterByTag(text-object) = %v, want [a b]", got)
+	}
+	if got := FilterByTag(puzzles, "missing"); len(got) != 0 {
+		t.Errorf("FilterByTag(missing) = %v, want empty", got)
+	}
+
+	tags := AllTags(puzzles)
+	want := []string{"Text-Object", "ciw", "dd", "text-object"}
+	if strings.Join(tags, ",") != strings.Join(want, ",") {
+		t.Errorf("AllTags = %q, want %q", tags, want)
+	}
+}
+
+func TestLoadFromDirAndMerge(t *testing.T) {
+	dir := t.TempDir()
+	good := `[{"id": "hjkl-01", "title": "Mine", "track": 1, "level": 1, "par": 1,
+	           "before": {"text": "a"}, "after": {"text": "b"}},
+	          {"id": "custom-01", "title": "Custom", "track": 1, "level": 2, "par": 1,
+	           "before": {"text": "a"}, "after": {"text": "b"}}]`
+	if err := os.WriteFile(filepath.Join(dir, "good.json"), []byte(good), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.json"), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	user, warnings := LoadFromDir(dir)
+	if len(user) != 2 {
+		t.Fatalf("LoadFromDir loaded %d puzzles, want 2", len(user))
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Error(), "bad.json") {
+		t.Errorf("warnings = %v, want one for bad.json", warnings)
+	}
+
+	builtin := []Puzzle{
+		{ID: "hjkl-01", Title: "Builtin", Track: 1, Level: 1},
+		{ID: "hjkl-02", Title: "Other", Track: 1, Level: 1},
+	}
+	merged := MergePuzzles(builtin, user)
+	if len(merged) != 3 {
+		t.Fatalf("merged %d puzzles, want 3", len(merged))
+	}
+	if merged[0].ID != "hjkl-01" || merged[0].Title != "Mine" {
+		t.Errorf("merged[0] = %s %q, want user override of hjkl-01", merged[0].ID, merged[0].Title)
+	}
+	if merged[2].ID != "custom-01" {
+		t.Errorf("merged[2] = %s, want custom-01 sorted after level 1", merged[2].ID)
+	}
+}
+
+func TestLoadFromFSReportsAllInvalidPuzzles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.json": {Data: []byte(`[{"id": "a-01", "par": 0, "before": {"text": "x"}, "after": {"text": "y"}}]`)},
+		"b.json": {Data: []byte(`[{"id": "b-01", "par": 1, "before": {"text": "same"}, "after": {"text": "same"}}]`)},
+	}
+	_, err := LoadFromFS(fsys, ".")
+	if err == nil {
+		t.Fatal("LoadFromFS() = nil error, want invalid puzzles")
+	}
+	for _, want := range []string{"a.json: puzzle a-01", "b.json: puzzle b-01"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestLoadFromFSNormalizesCRLF(t *testing.T) {
+	fsys := fstest.MapFS{
+		"win.json": {Data: []byte(`[{"id": "crlf-01", "par": 1, "before": {"text": "ab\r\ncd", "cursor": {"row": 0, "col": 1}}, "after": {"text": "ab\r\nc"}}]`)},
+	}
+	puzzles, err := LoadFromFS(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := puzzles[0]
+	if p.Before.Text != "ab\ncd" || p.After.Text != "ab\nc" {
+		t.Errorf("texts = %q, %q; want LF line endings", p.Before.Text, p.After.Text)
+	}
+	if !p.IsSolved("ab\nc") || !p.IsSolved("ab\r\nc") {
+		t.Error("IsSolved should accept the goal with either line ending")
+	}
+	if !Validate("ab\nc\n", "ab\r\nc\r\n") {
+		t.Error("Validate should compare CRLF target against LF buffer")
+	}
+}
+
+func TestLoadFromFSRejectsDuplicateIDs(t *testing.T) {
+	puzzle := `[{"id": "dup-01", "par": 1, "before": {"text": "x"}, "after": {"text": "y"}}]`
+	fsys := fstest.MapFS{
+		"one.json": {Data: []byte(puzzle)},
+		"two.json": {Data: []byte(puzzle)},
+	}
+	_, err := LoadFromFS(fsys, ".")
+	if err == nil {
+		t.Fatal("LoadFromFS() = nil error, want duplicate ID error")
+	}
+	if !strings.Contains(err.Error(), "dup-01 (in one.json, two.json)") {
+		t.Errorf("error %q does not name the duplicate ID and its files", err)
+	}
+}
+
+func TestSortByDifficulty(t *testing.T) {
+	puzzles := []Puzzle{{ID: "a", Difficulty: 2}, {ID: "b", Difficulty: 1}, {ID: "c", Difficulty: 2}, {ID: "d", Difficulty: 1}}
+	SortByDifficulty(puzzles)
+	var got string
+	for _, p := range puzzles {
+		got += p.ID
+	}
+	if got != "bdac" {
+		t.Errorf("SortByDifficulty order = %s, want bdac (stable within a difficulty)", got)
+	}
+}
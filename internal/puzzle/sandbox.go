@@ -0,0 +1,37 @@
+package puzzle
+
+// SandboxCategory marks the free-practice puzzle, which has no goal and is
+// never scored.
+const SandboxCategory = "sandbox"
+
+// sandboxText is the sample buffer the sandbox starts with.
+const sandboxText = `package main
+
+import "fmt"
+
+// greet prints a greeting for each name.
+func greet(names []string) {
+	for i, name := range names {
+		fmt.Printf("%d: hello, %s!\n", i+1, name)
+	}
+}
+
+func main() {
+	greet([]string{"alice", "bob", "carol"})
+}`
+
+// Sandbox returns the free-practice puzzle: a buffer of sample text to try
+// commands on, with no goal.
+func Sandbox() Puzzle {
+	return Puzzle{
+		ID:       "sandbox",
+		Title:    "Sandbox",
+		Category: SandboxCategory,
+		Before:   BeforeState{Text: sandboxText},
+	}
+}
+
+// IsSandbox reports whether p is the free-practice sandbox.
+func (p Puzzle) IsSandbox() bool {
+	return p.Category == SandboxCategory
+}
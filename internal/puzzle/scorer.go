@@ -2,11 +2,100 @@ package puzzle
 
 // Score calculates the star rating based on keystrokes and par.
 func Score(keystrokes, par int) StarRating {
+	return ScoreWithCutoff(keystrokes, par, DefaultTwoStarPar(par))
+}
+
+// ScoreWithCutoff calculates the star rating using an explicit two-star cutoff.
+func ScoreWithCutoff(keystrokes, par, twoStarPar int) StarRating {
 	if keystrokes <= par {
 		return ThreeStar
 	}
-	if keystrokes <= par*3/2 { // floor(par * 1.5)
+	if keystrokes <= twoStarPar {
+		return TwoStar
+	}
+	return OneStar
+}
+
+// DefaultTwoStarPar returns the two-star cutoff derived from par.
+func DefaultTwoStarPar(par int) int {
+	return par * 3 / 2 // floor(par * 1.5)
+}
+
+// TwoStarCutoff returns the most keystrokes that still earn two stars.
+func (p Puzzle) TwoStarCutoff() int {
+	if p.TwoStarPar > 0 {
+		return p.TwoStarPar
+	}
+	return DefaultTwoStarPar(p.Par)
+}
+
+// ScoreGolf rates a solve purely by distance from the optimal solution:
+// three stars at or under it, two stars within 10% over (at least one key),
+// one star otherwise.
+func ScoreGolf(keystrokes, optimalLen int) StarRating {
+	if keystrokes <= optimalLen {
+		return ThreeStar
+	}
+	if keystrokes <= optimalLen+max(1, optimalLen/10) {
 		return TwoStar
 	}
 	return OneStar
 }
+
+// golfTrack is the Vim Golf track, which scores in golf mode by default.
+const golfTrack = 4
+
+// EffectiveScoreMode returns the score mode in force for the puzzle. Golf mode
+// needs an optimal solution to measure against and falls back to par without one.
+func (p Puzzle) EffectiveScoreMode() ScoreMode {
+	mode := p.ScoreMode
+	if mode == "" && p.Track == golfTrack {
+		mode = ScoreModeGolf
+	}
+	if mode == ScoreModeGolf && p.OptimalLength() > 0 {
+		return ScoreModeGolf
+	}
+	return ScoreModePar
+}
+
+// Score rates a solve of this puzzle using its score mode, honoring the
+// two-star cutoff in par mode.
+func (p Puzzle) Score(keystrokes int) StarRating {
+	if p.EffectiveScoreMode() == ScoreModeGolf {
+		return ScoreGolf(keystrokes, p.OptimalLength())
+	}
+	return ScoreWithCutoff(keystrokes, p.Par, p.TwoStarCutoff())
+}
+
+// ScoreAssisted rates a solve like Score, charging for help used during the
+// attempt: a viewed hint adds HintPenalty keystrokes, and a viewed (or partly
+// revealed) solution caps the rating at two stars.
+func (p Puzzle) ScoreAssisted(keystrokes int, hintUsed, solutionUsed bool) StarRating {
+	if hintUsed {
+		keystrokes += p.HintPenalty()
+	}
+	stars := p.Score(keystrokes)
+	if solutionUsed {
+		stars = min(stars, TwoStar)
+	}
+	return stars
+}
+
+// HintPenalty returns the keystrokes a viewed hint adds when scoring: a
+// quarter of par, at least one.
+func (p Puzzle) HintPenalty() int {
+	return max(1, p.Par/4)
+}
+
+// OptimalLength returns the keystroke count of the optimal solution, or 0 if
+// the puzzle has none.
+func (p Puzzle) OptimalLength() int {
+	return KeyCount(p.OptimalSolution)
+}
+
+// IsPerfect reports whether a solve matched or beat the optimal solution.
+// Perfect solves are a badge on top of three stars, not a separate rating.
+func (p Puzzle) IsPerfect(keystrokes int) bool {
+	n := p.OptimalLength()
+	return n > 0 && keystrokes <= n
+}
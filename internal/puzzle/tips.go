@@ -0,0 +1,113 @@
+package puzzle
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxTips caps how many efficiency tips are returned.
+const maxTips = 3
+
+// countableMotions are single-key commands where a repeat can be written
+// with a count (e.g. "lll" as "3l").
+var countableMotions = map[string]bool{
+	"h": true, "j": true, "k": true, "l": true,
+	"w": true, "b": true, "e": true, "W": true, "B": true, "E": true,
+	"x": true, "X": true, "p": true, "P": true,
+	"{": true, "}": true, "(": true, ")": true,
+	";": true, ",": true, "n": true, "N": true,
+}
+
+// insertCommands are normal-mode commands that enter insert mode, besides
+// any change operator ("cw", "ciw", ...).
+var insertCommands = map[string]bool{
+	"i": true, "a": true, "I": true, "A": true, "o": true, "O": true,
+	"s": true, "S": true, "C": true, "R": true, "gi": true, "gI": true,
+}
+
+// EfficiencyTips compares a recorded key log (one entry per command sent)
+// with the optimal solution and points out repeated commands that a count
+// would have shortened, e.g. "you used `lll` where `3l` was shorter". It is
+// a simple heuristic, not an edit-distance analysis, and returns nothing
+// when the log was already no longer than the optimal solution.
+func EfficiencyTips(keyLog []string, optimal string) []string {
+	used := 0
+	for _, k := range keyLog {
+		used += KeyCount(k)
+	}
+	if optimal != "" && used <= KeyCount(optimal) {
+		return nil
+	}
+
+	var tips []string
+	seen := make(map[string]bool)
+	normal := normalModeEntries(keyLog)
+	for i := 0; i < len(normal); {
+		j := i
+		for j < len(normal) && normal[j] == normal[i] {
+			j++
+		}
+		cmd, n := normal[i], j-i
+		i = j
+		if cmd == "" || !isCountable(cmd) {
+			continue
+		}
+		keys := KeyCount(cmd)
+		if len(strconv.Itoa(n))+keys >= n*keys {
+			continue
+		}
+		tip := fmt.Sprintf("you used `%s` where `%d%s` was shorter", strings.Repeat(cmd, n), n, cmd)
+		if !seen[tip] {
+			seen[tip] = true
+			tips = append(tips, tip)
+		}
+		if len(tips) == maxTips {
+			break
+		}
+	}
+	return tips
+}
+
+// normalModeEntries returns the key log with entries typed outside normal
+// mode (insert text, command lines, searches) blanked out, so runs are only
+// found among normal-mode commands.
+func normalModeEntries(keyLog []string) []string {
+	out := make([]string, len(keyLog))
+	inInsert, inCmdline := false, false
+	for i, k := range keyLog {
+		switch {
+		case inInsert:
+			inInsert = k != "<Esc>" && k != "<C-c>" && k != "<C-[>"
+		case inCmdline:
+			inCmdline = k != "<CR>" && k != "<Enter>" && k != "<Esc>" && k != "<C-c>"
+		default:
+			cmd := strings.TrimLeft(k, "0123456789")
+			switch {
+			case insertCommands[cmd] || (strings.HasPrefix(cmd, "c") && len(cmd) > 1):
+				inInsert = true
+			case cmd == ":" || cmd == "/" || cmd == "?":
+				inCmdline = true
+			default:
+				out[i] = k
+			}
+		}
+	}
+	return out
+}
+
+// isCountable reports whether a repeated command could take a count instead:
+// a countable motion, a doubled line operator, or a delete/yank/indent of one.
+func isCountable(cmd string) bool {
+	if countableMotions[cmd] {
+		return true
+	}
+	switch cmd {
+	case "dd", "yy", ">>", "<<":
+		return true
+	}
+	if len(cmd) == 2 && strings.ContainsRune("dy", rune(cmd[0])) {
+		return countableMotions[cmd[1:]] && !strings.ContainsRune("xXpP;,nN", rune(cmd[1]))
+	}
+	return false
+}
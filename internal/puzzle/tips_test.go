@@ -0,0 +1,47 @@
+package puzzle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEfficiencyTips(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyLog  []string
+		optimal string
+		want    []string
+	}{
+		{
+			name:    "repeated motion",
+			keyLog:  []string{"l", "l", "l", "x"},
+			optimal: "3lx",
+			want:    []string{"you used `lll` where `3l` was shorter"},
+		},
+		{
+			name:    "two repeats are not shorter with a count",
+			keyLog:  []string{"j", "j", "dd", "dd", "dd"},
+			optimal: "jj3dd",
+			want:    []string{"you used `dddddd` where `3dd` was shorter"},
+		},
+		{
+			name:    "insert text is ignored",
+			keyLog:  []string{"i", "w", "w", "w", "<Esc>", "w", "w"},
+			optimal: "iwww<Esc>",
+			want:    nil,
+		},
+		{
+			name:    "already optimal",
+			keyLog:  []string{"l", "l", "l"},
+			optimal: "lll",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EfficiencyTips(tt.keyLog, tt.optimal); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EfficiencyTips = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package puzzle
+
+// TutorialCategory marks the built-in puzzles of the first-run tutorial.
+const TutorialCategory = "tutorial"
+
+// Tutorial returns the first-run tutorial puzzles: trivial edits that show
+// how a puzzle is played before any scored ones.
+func Tutorial() []Puzzle {
+	return []Puzzle{
+		{
+			ID:                  "tutorial-01",
+			Title:               "Your First Edit",
+			Category:            TutorialCategory,
+			Before:              BeforeState{Text: "cat"},
+			After:               AfterState{Text: "bat"},
+			Par:                 2,
+			Hint:                "Press r, then b, to replace the character under the cursor",
+			OptimalSolution:     "rb",
+			SolutionExplanation: "rb — 'replace': replaces the character under the cursor with b.",
+		},
+		{
+			ID:                  "tutorial-02",
+			Title:               "Move and Delete",
+			Category:            TutorialCategory,
+			Before:              BeforeState{Text: "vimXgym"},
+			After:               AfterState{Text: "vimgym"},
+			Par:                 3,
+			Hint:                "Move right with l (or jump with fX), then press x to delete the X",
+			OptimalSolution:     "fXx",
+			SolutionExplanation: "fX — jumps to the next X on the line. x — deletes the character under the cursor.",
+		},
+	}
+}
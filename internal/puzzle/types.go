@@ -10,6 +10,21 @@ type CursorPos struct {
 type BeforeState struct {
 	Text   string    `json:"text"`
 	Cursor CursorPos `json:"cursor"`
+	// CursorChoices, when set, replaces Cursor with a start picked per attempt,
+	// so motion puzzles can't be solved from memory.
+	CursorChoices []CursorPos `json:"cursorChoices,omitempty"`
+	// Seed selects which of CursorChoices is used; it is set per attempt.
+	Seed int `json:"-"`
+}
+
+// StartCursor returns the cursor to start from: Cursor, or the entry of
+// CursorChoices selected by Seed.
+func (b BeforeState) StartCursor() CursorPos {
+	n := len(b.CursorChoices)
+	if n == 0 {
+		return b.Cursor
+	}
+	return b.CursorChoices[(b.Seed%n+n)%n]
 }
 
 // AfterState represents the goal state of a puzzle.
@@ -32,8 +47,69 @@ type Puzzle struct {
 	OptimalSolution     string   `json:"optimalSolution"`
 	SolutionExplanation string   `json:"solutionExplanation"`
 	Tags                []string `json:"tags"`
+	// Language enables syntax coloring of the buffers (e.g. "go", "python").
+	Language string `json:"language,omitempty"`
+	// ShowCounts enables the word/char count display by default.
+	ShowCounts bool `json:"showCounts,omitempty"`
+	// StrictPrefix rejects input once the buffer stops being a prefix of the goal,
+	// like a typing test. Intended for typing drills that start from an empty buffer.
+	StrictPrefix bool `json:"strictPrefix,omitempty"`
+	// ValidationMode controls how the buffer is compared to the goal (default exact).
+	ValidationMode ValidationMode `json:"validationMode,omitempty"`
+	// AcceptableAfter lists alternative goal states that also clear the puzzle.
+	AcceptableAfter []AfterState `json:"acceptableAfter,omitempty"`
+	// AfterCursor, when set, must also match the final cursor position.
+	AfterCursor *CursorPos `json:"afterCursor,omitempty"`
+	// EfficiencyHint suggests how to beat par once the puzzle has been solved
+	// over par EfficiencyHintAfter times (default 3).
+	EfficiencyHint      string `json:"efficiencyHint,omitempty"`
+	EfficiencyHintAfter int    `json:"efficiencyHintAfter,omitempty"`
+	// ScoreMode selects par-based or golf scoring (default par, golf on track 4).
+	ScoreMode ScoreMode `json:"scoreMode,omitempty"`
+	// TwoStarPar overrides the two-star keystroke cutoff (default floor(1.5 × par)).
+	TwoStarPar int `json:"twoStarPar,omitempty"`
+	// CommunityPar is the average keystrokes real users took (informational only).
+	CommunityPar int `json:"communityPar,omitempty"`
+	// Steps are intermediate goal states that must be reached, in order,
+	// before the final After state. Each completed step is a checkpoint.
+	Steps []AfterState `json:"steps,omitempty"`
+	// RequireFinalNewline makes validation strict about a trailing newline:
+	// true requires the buffer to end with exactly one (an empty last line), false
+	// forbids it. Unset keeps the lenient default that ignores it.
+	RequireFinalNewline *bool `json:"requireFinalNewline,omitempty"`
+}
+
+// defaultEfficiencyHintAfter is the number of over-par solves before the
+// efficiency hint is shown.
+const defaultEfficiencyHintAfter = 3
+
+// EfficiencyHintThreshold returns how many over-par solves unlock the efficiency hint.
+func (p Puzzle) EfficiencyHintThreshold() int {
+	if p.EfficiencyHintAfter > 0 {
+		return p.EfficiencyHintAfter
+	}
+	return defaultEfficiencyHintAfter
 }
 
+// ScoreMode selects how keystrokes are turned into stars.
+type ScoreMode string
+
+const (
+	// ScoreModePar rates against par and the two-star cutoff.
+	ScoreModePar ScoreMode = "par"
+	// ScoreModeGolf rates by distance from the optimal solution's length.
+	ScoreModeGolf ScoreMode = "golf"
+)
+
+// ValidationMode selects how leniently buffer text is compared to the goal.
+type ValidationMode string
+
+const (
+	ValidationExact        ValidationMode = "exact"
+	ValidationTrimTrailing ValidationMode = "trim-trailing"
+	ValidationIgnoreIndent ValidationMode = "ignore-indent"
+)
+
 // StarRating represents the score for a puzzle completion.
 type StarRating int
 
@@ -1,8 +1,150 @@
 package puzzle
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 // Validate checks if the current buffer text matches the target text.
 func Validate(current, target string) bool {
-	return strings.TrimRight(current, "\n") == strings.TrimRight(target, "\n")
+	return ValidateMode(current, target, ValidationExact)
+}
+
+// ValidateMode checks if the current buffer text matches the target text
+// using the given validation mode. An empty mode behaves like ValidationExact.
+func ValidateMode(current, target string, mode ValidationMode) bool {
+	current = strings.TrimRight(NormalizeLineEndings(current), "\n")
+	target = strings.TrimRight(NormalizeLineEndings(target), "\n")
+	switch mode {
+	case ValidationTrimTrailing:
+		return normalizeLines(current, trimTrailing) == normalizeLines(target, trimTrailing)
+	case ValidationIgnoreIndent:
+		return normalizeLines(current, trimIndent) == normalizeLines(target, trimIndent)
+	default:
+		return current == target
+	}
+}
+
+// IsSolved checks the buffer text against the puzzle's goal and any
+// acceptable alternatives. Scoring is the same whichever goal matched.
+func (p Puzzle) IsSolved(current string) bool {
+	current = NormalizeLineEndings(current)
+	if require := p.RequireFinalNewline; require != nil {
+		// Strict means exactly one newline when required, since the
+		// comparison below ignores any trailing newlines.
+		oneNewline := strings.HasSuffix(current, "\n") && !strings.HasSuffix(current, "\n\n")
+		if *require && !oneNewline || !*require && strings.HasSuffix(current, "\n") {
+			return false
+		}
+	}
+	if ValidateMode(current, p.After.Text, p.ValidationMode) {
+		return true
+	}
+	for _, alt := range p.AcceptableAfter {
+		if ValidateMode(current, alt.Text, p.ValidationMode) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePrefix checks if the current buffer text is a prefix of the target text.
+func ValidatePrefix(current, target string) bool {
+	return strings.HasPrefix(NormalizeLineEndings(target), strings.TrimRight(NormalizeLineEndings(current), "\n"))
+}
+
+// NormalizeLineEndings converts CRLF and lone CR line endings to LF.
+func NormalizeLineEndings(text string) string {
+	if !strings.Contains(text, "\r") {
+		return text
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
+
+// normalizeText rewrites every text field of p to LF line endings, so packs
+// authored on Windows render and validate like any other.
+func normalizeText(p *Puzzle) {
+	p.Before.Text = NormalizeLineEndings(p.Before.Text)
+	p.After.Text = NormalizeLineEndings(p.After.Text)
+	for i := range p.AcceptableAfter {
+		p.AcceptableAfter[i].Text = NormalizeLineEndings(p.AcceptableAfter[i].Text)
+	}
+	for i := range p.Steps {
+		p.Steps[i].Text = NormalizeLineEndings(p.Steps[i].Text)
+	}
+}
+
+func trimTrailing(line string) string {
+	return strings.TrimRight(line, " \t")
+}
+
+func trimIndent(line string) string {
+	return strings.TrimLeft(line, " \t")
+}
+
+func normalizeLines(text string, fn func(string) string) string {
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = fn(l)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ValidateDefinition checks that a puzzle definition is well-formed: it has an
+// ID and a positive par, its before state doesn't already solve it, and the
+// starting cursor (and every cursor choice) lies within the before text.
+func ValidateDefinition(p Puzzle) error {
+	var errs []error
+	if strings.TrimSpace(p.ID) == "" {
+		errs = append(errs, errors.New("missing id"))
+	}
+	if p.Par <= 0 {
+		errs = append(errs, fmt.Errorf("par must be positive, got %d", p.Par))
+	}
+	if p.TwoStarPar != 0 && p.TwoStarPar < p.Par {
+		errs = append(errs, fmt.Errorf("twoStarPar %d is below par %d", p.TwoStarPar, p.Par))
+	}
+	if p.IsSolved(p.Before.Text) {
+		errs = append(errs, errors.New("before text already matches the goal"))
+	}
+	lines := strings.Split(NormalizeLineEndings(p.Before.Text), "\n")
+	if err := cursorInBounds(lines, p.Before.Cursor); err != nil {
+		errs = append(errs, err)
+	}
+	for i, c := range p.Before.CursorChoices {
+		if err := cursorInBounds(lines, c); err != nil {
+			errs = append(errs, fmt.Errorf("cursorChoices[%d]: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// cursorInBounds checks that c lies on a character of lines (or column 0 of
+// an empty line).
+func cursorInBounds(lines []string, c CursorPos) error {
+	if c.Row < 0 || c.Row >= len(lines) {
+		return fmt.Errorf("cursor row %d out of range (0-%d)", c.Row, len(lines)-1)
+	}
+	if maxCol := max(len(lines[c.Row])-1, 0); c.Col < 0 || c.Col > maxCol {
+		return fmt.Errorf("cursor col %d out of range (0-%d) on row %d", c.Col, maxCol, c.Row)
+	}
+	return nil
+}
+
+// validateDefinitions checks every puzzle and returns one error listing all
+// invalid puzzles, or nil if they are all valid.
+func validateDefinitions(puzzles []Puzzle, source string) error {
+	var errs []error
+	for i, p := range puzzles {
+		if err := ValidateDefinition(p); err != nil {
+			name := p.ID
+			if name == "" {
+				name = fmt.Sprintf("#%d", i)
+			}
+			errs = append(errs, fmt.Errorf("%s: puzzle %s: %s", source, name, strings.ReplaceAll(err.Error(), "\n", "; ")))
+		}
+	}
+	return errors.Join(errs...)
 }
@@ -0,0 +1,162 @@
+package puzzle
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vimgym/vimgym/puzzles"
+)
+
+func TestValidateMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     ValidationMode
+		current  string
+		target   string
+		expected bool
+	}{
+		{"empty mode is exact", "", "a \nb", "a\nb", false},
+		{"exact match", ValidationExact, "a\nb", "a\nb", true},
+		{"exact trailing space", ValidationExact, "a \nb", "a\nb", false},
+		{"trim-trailing spaces", ValidationTrimTrailing, "a  \nb\t", "a\nb", true},
+		{"trim-trailing keeps indent", ValidationTrimTrailing, "  a\nb", "a\nb", false},
+		{"trim-trailing content mismatch", ValidationTrimTrailing, "a \nc", "a\nb", false},
+		{"ignore-indent spaces vs tabs", ValidationIgnoreIndent, "\tif x {\n\t\ty()\n\t}", "if x {\n    y()\n}", true},
+		{"ignore-indent content mismatch", ValidationIgnoreIndent, "    foo()", "bar()", false},
+		{"ignore-indent inner whitespace", ValidationIgnoreIndent, "  a  b", "a b", false},
+		{"ignore-indent trailing newline", ValidationIgnoreIndent, "  a\n", "a", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidateMode(tt.current, tt.target, tt.mode); got != tt.expected {
+				t.Errorf("ValidateMode(%q, %q, %q) = %v, want %v", tt.current, tt.target, tt.mode, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsSolved(t *testing.T) {
+	single := Puzzle{After: AfterState{Text: "foo()"}}
+	multi := Puzzle{
+		After:           AfterState{Text: "foo()"},
+		AcceptableAfter: []AfterState{{Text: "foo() "}, {Text: "foo();"}},
+	}
+
+	tests := []struct {
+		name     string
+		p        Puzzle
+		current  string
+		expected bool
+	}{
+		{"single target match", single, "foo()", true},
+		{"single target alternative rejected", single, "foo();", false},
+		{"multi primary match", multi, "foo()", true},
+		{"multi alternative match", multi, "foo();", true},
+		{"multi no match", multi, "bar()", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.IsSolved(tt.current); got != tt.expected {
+				t.Errorf("IsSolved(%q) = %v, want %v", tt.current, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateDefinition(t *testing.T) {
+	valid := Puzzle{
+		ID:     "ok-01",
+		Par:    2,
+		Before: BeforeState{Text: "abc\ndef", Cursor: CursorPos{Row: 1, Col: 2}},
+		After:  AfterState{Text: "abc"},
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(p *Puzzle)
+		wantErr string
+	}{
+		{"valid", func(p *Puzzle) {}, ""},
+		{"missing id", func(p *Puzzle) { p.ID = "" }, "missing id"},
+		{"zero par", func(p *Puzzle) { p.Par = 0 }, "par must be positive"},
+		{"pre-solved", func(p *Puzzle) { p.After.Text = p.Before.Text + "\n" }, "already matches"},
+		{"row out of range", func(p *Puzzle) { p.Before.Cursor.Row = 2 }, "cursor row"},
+		{"col out of range", func(p *Puzzle) { p.Before.Cursor.Col = 3 }, "cursor col"},
+		{"empty line col 0", func(p *Puzzle) { p.Before.Text = "abc\n\ndef"; p.Before.Cursor.Col = 0 }, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid
+			tt.mutate(&p)
+			err := ValidateDefinition(p)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateDefinition() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateDefinition() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEmbeddedPuzzlesAreValid(t *testing.T) {
+	if _, err := LoadFromFS(puzzles.FS, "."); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIsSolvedFinalNewline(t *testing.T) {
+	require, forbid := true, false
+	tests := []struct {
+		name     string
+		policy   *bool
+		current  string
+		expected bool
+	}{
+		{"lenient with newline", nil, "foo\n", true},
+		{"lenient without newline", nil, "foo", true},
+		{"required and present", &require, "foo\n", true},
+		{"required but missing", &require, "foo", false},
+		{"required but doubled", &require, "foo\n\n\n", false},
+		{"forbidden and absent", &forbid, "foo", true},
+		{"forbidden but present", &forbid, "foo\n", false},
+		{"forbidden but several present", &forbid, "foo\n\n", false},
+		{"required but content differs", &require, "bar\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Puzzle{After: AfterState{Text: "foo"}, RequireFinalNewline: tt.policy}
+			if got := p.IsSolved(tt.current); got != tt.expected {
+				t.Errorf("IsSolved(%q) = %v, want %v", tt.current, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStartCursorChoices(t *testing.T) {
+	b := BeforeState{Text: "abc def", Cursor: CursorPos{Col: 1}}
+	if got := b.StartCursor(); got != (CursorPos{Col: 1}) {
+		t.Errorf("StartCursor without choices = %+v, want Cursor", got)
+	}
+
+	b.CursorChoices = []CursorPos{{Col: 0}, {Col: 4}, {Col: 6}}
+	for seed, want := range map[int]int{0: 0, 1: 4, 5: 6, -1: 6} {
+		b.Seed = seed
+		if got := b.StartCursor(); got.Col != want {
+			t.Errorf("StartCursor(seed %d) col = %d, want %d", seed, got.Col, want)
+		}
+	}
+
+	p := Puzzle{ID: "p", Par: 1, Before: b, After: AfterState{Text: "x"}}
+	p.Before.CursorChoices = append(p.Before.CursorChoices, CursorPos{Col: 9})
+	if err := ValidateDefinition(p); err == nil || !strings.Contains(err.Error(), "cursorChoices[3]") {
+		t.Errorf("ValidateDefinition = %v, want out-of-range cursorChoices[3]", err)
+	}
+}
@@ -1,9 +1,12 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	nvimclient "github.com/vimgym/vimgym/internal/nvim"
 	"github.com/vimgym/vimgym/internal/puzzle"
 	"github.com/vimgym/vimgym/internal/progress"
@@ -14,8 +17,16 @@ type screen int
 const (
 	screenTrack screen = iota
 	screenPuzzle
+	screenHelp
+	screenSettings
+	screenError
+	screenStats
+	screenTutorial
 )
 
+// newNvim starts the Neovim instance for a puzzle (replaced in tests).
+var newNvim = nvimclient.New
+
 // App is the main Bubble Tea model.
 type App struct {
 	screen     screen
@@ -24,9 +35,28 @@ type App struct {
 	nvim       *nvimclient.Client
 	puzzles    []puzzle.Puzzle
 	progress   *progress.Store
+	settings   *progress.Settings
+	settingsView SettingsView
 	width      int
 	height     int
-	err        error
+	// err is why Neovim failed to start; errPuzzle is the puzzle to retry.
+	err       error
+	errPuzzle puzzle.Puzzle
+	// statsStatus reports the result of the last export from the stats screen.
+	statsStatus string
+
+	// tutorial is the first-run tutorial; inTutorial is set while it runs,
+	// so its puzzles return to it and aren't recorded as progress.
+	tutorial   TutorialView
+	inTutorial bool
+	// startWith is the puzzle to open on launch instead of the track list.
+	startWith *puzzle.Puzzle
+	// puzzleSet names the external puzzle file in use (see SetPuzzleSet).
+	puzzleSet string
+
+	// helpReturn is the screen under the help overlay; helpScroll is its scroll offset.
+	helpReturn screen
+	helpScroll int
 }
 
 // NewApp creates the main application model.
@@ -41,17 +71,70 @@ func NewApp(puzzles []puzzle.Puzzle) (*App, error) {
 		return nil, fmt.Errorf("loading progress: %w", err)
 	}
 
+	// Unreadable settings fall back to the defaults rather than blocking startup.
+	settings, _ := progress.LoadSettings(prog.Dir())
+	applySettings(*settings)
+	prog.SetTrackStarGates(settings.TrackStarGates)
+
 	app := &App{
 		screen:   screenTrack,
 		puzzles:  puzzles,
 		progress: prog,
+		settings: settings,
 	}
 	app.trackView = NewTrackView(puzzles, prog)
+	if w := prog.Warning(); w != nil {
+		app.trackView.status = "Warning: " + w.Error()
+	}
+	// First run: teach the basics before the track list.
+	if prog.IsEmpty() && !settings.TutorialDone {
+		app.startTutorial()
+	}
 
 	return app, nil
 }
 
+// OpenPuzzle makes the app start in the puzzle with id rather than the
+// track list, skipping the first-run tutorial. Leaving the puzzle returns
+// to its level as usual.
+func (a *App) OpenPuzzle(id string) error {
+	p, ok := puzzle.FindByID(a.puzzles, id)
+	if !ok {
+		return fmt.Errorf("no puzzle with ID %q", id)
+	}
+	a.inTutorial = false
+	a.screen = screenTrack
+	status := a.trackView.status
+	a.trackView = a.levelTrackView(p)
+	a.trackView.status = status
+	a.startWith = &p
+	return nil
+}
+
+// OpenLevel makes the app start on the puzzle list of level, skipping the
+// first-run tutorial.
+func (a *App) OpenLevel(level int) error {
+	if len(puzzle.GetPuzzlesForLevel(a.puzzles, level)) == 0 {
+		return fmt.Errorf("no level %d", level)
+	}
+	a.inTutorial = false
+	a.screen = screenTrack
+	a.trackView = a.trackView.openLevel(level)
+	return nil
+}
+
+// SetPuzzleSet names the external puzzle file in use, so the level list
+// shows which set is being played. Empty means the built-in puzzles.
+func (a *App) SetPuzzleSet(name string) {
+	a.puzzleSet = name
+	a.trackView.puzzleSet = name
+}
+
 func (a App) Init() tea.Cmd {
+	if p := a.startWith; p != nil {
+		// Started through Update, so Neovim errors get the usual screen.
+		return func() tea.Msg { return selectedPuzzle{puzzle: *p} }
+	}
 	return nil
 }
 
@@ -61,11 +144,28 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.width = msg.Width
 		a.height = msg.Height
 
+	case nvimChangedMsg:
+		// Keep listening whatever the screen; only a puzzle needs the sync.
+		if msg.client != a.nvim {
+			return a, nil // from a client that has since been restarted
+		}
+		var cmd tea.Cmd
+		if a.screen == screenPuzzle || a.screen == screenHelp && a.helpReturn == screenPuzzle {
+			a.puzzleView, cmd = a.puzzleView.Update(nvimSyncMsg{})
+		}
+		return a, tea.Batch(cmd, waitForNvimChange(msg.client))
+
 	case tea.KeyMsg:
 		// Global quit
 		if msg.String() == "ctrl+c" {
 			return a, tea.Quit
 		}
+		if a.screen != screenHelp && a.opensHelp(msg.String()) {
+			a.helpReturn = a.screen
+			a.helpScroll = 0
+			a.screen = screenHelp
+			return a, nil
+		}
 	}
 
 	switch a.screen {
@@ -73,6 +173,16 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a.updateTrack(msg)
 	case screenPuzzle:
 		return a.updatePuzzle(msg)
+	case screenHelp:
+		return a.updateHelp(msg)
+	case screenSettings:
+		return a.updateSettings(msg)
+	case screenError:
+		return a.updateError(msg)
+	case screenStats:
+		return a.updateStats(msg)
+	case screenTutorial:
+		return a.updateTutorial(msg)
 	}
 
 	return a, nil
@@ -81,18 +191,16 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (a App) updateTrack(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case selectedPuzzle:
-		// Start puzzle - create Neovim instance
-		nv, err := nvimclient.New()
-		if err != nil {
-			a.err = fmt.Errorf("starting neovim: %w", err)
-			return a, nil
-		}
-		a.nvim = nv
-		a.screen = screenPuzzle
-		a.puzzleView = NewPuzzleView(msg.puzzle, nv, a.progress, a.puzzles)
-		a.puzzleView.width = a.width
-		a.puzzleView.height = a.height
-		return a, a.puzzleView.Init()
+		return a.startPuzzle(msg.puzzle)
+	case openStatsMsg:
+		a.screen = screenStats
+		a.statsStatus = ""
+		return a, nil
+	case openSettingsMsg:
+		a.screen = screenSettings
+		a.settingsView = NewSettingsView(*a.settings)
+		a.settingsView.width = a.width
+		return a, nil
 	default:
 		var cmd tea.Cmd
 		a.trackView, cmd = a.trackView.Update(msg)
@@ -103,33 +211,39 @@ func (a App) updateTrack(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (a App) updatePuzzle(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case puzzleExitMsg:
+		if a.inTutorial {
+			a.tutorial = a.tutorial.finish(a.puzzleView.puzzle.ID, a.puzzleView.state == stateCleared)
+			a.screen = screenTutorial
+			return a, nil
+		}
+		if msg.nextUnsolved {
+			if next, ok := nextUnsolvedPuzzle(a.puzzles, a.progress, a.puzzleView.puzzle); ok && a.nvim != nil {
+				a.puzzleView = a.newPuzzleView(next)
+				return a, a.puzzleView.Init()
+			}
+		}
 		if msg.next {
 			if next, ok := nextPuzzleInLevel(a.puzzles, a.puzzleView.puzzle); ok && a.nvim != nil {
-				a.puzzleView = NewPuzzleView(next, a.nvim, a.progress, a.puzzles)
+				a.puzzleView = a.newPuzzleView(next)
 				return a, a.puzzleView.Init()
 			}
 			// No next puzzle in this level: go to level selection for current track.
 			a.screen = screenTrack
-			a.trackView = trackViewForLevel(a.puzzles, a.progress, a.puzzleView.puzzle)
-			a.trackView.width = a.width
-			a.trackView.height = a.height
-			if a.nvim != nil {
-				a.nvim.Close()
-				a.nvim = nil
-			}
+			a.trackView = a.levelTrackView(a.puzzleView.puzzle)
 			return a, nil
 		}
-		// Clean up Neovim and go back to track view
-		if a.nvim != nil {
-			a.nvim.Close()
-			a.nvim = nil
-		}
+		// Go back to track view; Neovim stays up for the next puzzle.
 		a.screen = screenTrack
 		// Refresh track view with updated progress, cursor on current level
-		a.trackView = trackViewForLevel(a.puzzles, a.progress, a.puzzleView.puzzle)
-		a.trackView.width = a.width
-		a.trackView.height = a.height
+		a.trackView = a.levelTrackView(a.puzzleView.puzzle)
 		return a, nil
+	case nvimRestartMsg:
+		// Closing a hung process may block, so it is left to finish on its own.
+		if old := a.nvim; old != nil {
+			go old.Close()
+		}
+		a.nvim = nil
+		return a.startPuzzle(a.puzzleView.puzzle)
 	default:
 		var cmd tea.Cmd
 		a.puzzleView, cmd = a.puzzleView.Update(msg)
@@ -137,6 +251,216 @@ func (a App) updatePuzzle(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 }
 
+// startPuzzle opens p, starting Neovim on first use and reusing it after
+// that. It shows the error screen if Neovim can't be started.
+func (a App) startPuzzle(p puzzle.Puzzle) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	if a.nvim == nil {
+		nv, err := newNvim()
+		if err != nil {
+			a.err = err
+			a.errPuzzle = p
+			a.screen = screenError
+			return a, nil
+		}
+		a.nvim = nv
+		cmd = waitForNvimChange(nv)
+	}
+	a.err = nil
+	// The terminal may have been resized while Neovim sat idle.
+	a.nvim.ResizeUI(a.width, a.height)
+	a.screen = screenPuzzle
+	a.puzzleView = a.newPuzzleView(p)
+	a.puzzleView.width = a.width
+	a.puzzleView.height = a.height
+	return a, tea.Batch(cmd, a.puzzleView.Init())
+}
+
+// nvimChangedMsg reports that Neovim pushed a change to the buffer, cursor
+// or mode of client.
+type nvimChangedMsg struct {
+	client *nvimclient.Client
+}
+
+// waitForNvimChange waits for the next change Neovim pushes. It stops once
+// the client is closed; each nvimChangedMsg re-arms it.
+func waitForNvimChange(nv *nvimclient.Client) tea.Cmd {
+	return func() tea.Msg {
+		if !nv.WaitForChange() {
+			return nil
+		}
+		return nvimChangedMsg{client: nv}
+	}
+}
+
+// updateError handles the Neovim startup error screen: r retries, esc
+// returns to the track list.
+func (a App) updateError(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return a, nil
+	}
+	switch key.String() {
+	case "r":
+		return a.startPuzzle(a.errPuzzle)
+	case "esc", "q":
+		a.err = nil
+		a.screen = screenTrack
+		if a.inTutorial {
+			a.screen = screenTutorial
+		}
+		return a, nil
+	}
+	return a, nil
+}
+
+// renderError explains why Neovim failed to start and how to recover.
+func (a App) renderError() string {
+	width := a.width
+	if width <= 0 {
+		width = 80
+	}
+	cause := "Neovim started but could not be set up; it may be too old or misconfigured."
+	switch {
+	case errors.Is(a.err, nvimclient.ErrNvimNotFound):
+		cause = "Neovim is not installed, or `nvim` is not on your PATH."
+	case errors.Is(a.err, nvimclient.ErrUnsupportedNvimVersion):
+		cause = fmt.Sprintf("Your Neovim is too old; VimGym needs %s or newer.", nvimclient.MinVersion)
+	}
+	lines := []string{
+		titleStyle.MaxWidth(width).Render("Couldn't start Neovim"),
+		lipgloss.NewStyle().Width(width).Render(cause),
+		"",
+		mutedStyle.Width(width).Render(fmt.Sprintf("Error: %v", a.err)),
+		helpStyle.MaxWidth(width).Render("  r: retry  esc: back"),
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Close shuts down the session's Neovim, if one was started.
+func (a App) Close() error {
+	if a.nvim == nil {
+		return nil
+	}
+	return a.nvim.Close()
+}
+
+// newPuzzleView creates a puzzle view using the app's Neovim, progress and
+// settings. Tutorial puzzles are played without recording progress.
+func (a App) newPuzzleView(p puzzle.Puzzle) PuzzleView {
+	// A nil client must stay a nil interface, not a non-nil one holding nil.
+	var nv NvimController
+	if a.nvim != nil {
+		nv = a.nvim
+	}
+	prog, all := a.progress, a.puzzles
+	if a.inTutorial {
+		prog, all = nil, a.tutorial.puzzles
+	}
+	pv := NewPuzzleView(p, nv, prog, all)
+	if a.settings != nil {
+		pv.settings = *a.settings
+	}
+	return pv
+}
+
+// updateSettings runs the settings screen and saves or discards its edits.
+func (a App) updateSettings(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if done, ok := msg.(settingsDoneMsg); ok {
+		a.screen = screenTrack
+		if done.tutorial {
+			a.startTutorial()
+		}
+		if done.save {
+			*a.settings = done.settings
+			applySettings(*a.settings)
+			if err := a.settings.Save(); err != nil {
+				a.trackView.status = fmt.Sprintf("Saving settings failed: %v", err)
+			} else {
+				a.trackView.status = "Settings saved."
+			}
+		}
+		return a, tea.ClearScreen
+	}
+	var cmd tea.Cmd
+	a.settingsView, cmd = a.settingsView.Update(msg)
+	return a, cmd
+}
+
+// startTutorial shows the first-run tutorial from its first puzzle.
+func (a *App) startTutorial() {
+	a.tutorial = NewTutorialView()
+	a.tutorial.width = a.width
+	a.inTutorial = true
+	a.screen = screenTutorial
+}
+
+// updateTutorial runs the tutorial screen. Its puzzles open like any other;
+// finishing or skipping it is remembered so it only shows once.
+func (a App) updateTutorial(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case selectedPuzzle:
+		return a.startPuzzle(msg.puzzle)
+	case tutorialDoneMsg:
+		a.inTutorial = false
+		a.screen = screenTrack
+		if a.settings != nil && !a.settings.TutorialDone {
+			a.settings.TutorialDone = true
+			if err := a.settings.Save(); err != nil {
+				a.trackView.status = fmt.Sprintf("Saving settings failed: %v", err)
+			}
+		}
+		return a, tea.ClearScreen
+	default:
+		var cmd tea.Cmd
+		a.tutorial, cmd = a.tutorial.Update(msg)
+		return a, cmd
+	}
+}
+
+// opensHelp reports whether key opens the help overlay on the current screen.
+// F1 always does; ? does on menus and the clear screen, but while solving
+// it is Vim's backward search.
+func (a App) opensHelp(key string) bool {
+	switch key {
+	case "f1":
+		return true
+	case "?":
+		switch a.screen {
+		case screenTrack:
+			return !a.trackView.exportPrompt && !a.trackView.filterInput
+		case screenStats, screenTutorial:
+			return true
+		case screenPuzzle:
+			return a.puzzleView.state == stateCleared
+		}
+	}
+	return false
+}
+
+// updateHelp scrolls and closes the help overlay. Other messages still reach
+// the screen underneath, so puzzle syncs and timers keep working.
+func (a App) updateHelp(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc", "?", "q", "f1":
+			a.screen = a.helpReturn
+			return a, tea.ClearScreen
+		case "down", "j":
+			a.helpScroll = min(a.helpScroll+1, a.maxHelpScroll())
+		case "up", "k":
+			if a.helpScroll > 0 {
+				a.helpScroll--
+			}
+		}
+		return a, nil
+	}
+	if a.helpReturn == screenPuzzle {
+		return a.updatePuzzle(msg)
+	}
+	return a.updateTrack(msg)
+}
+
 func nextPuzzleInLevel(all []puzzle.Puzzle, current puzzle.Puzzle) (puzzle.Puzzle, bool) {
 	index := -1
 	for i, p := range all {
@@ -160,6 +484,38 @@ func nextPuzzleInLevel(all []puzzle.Puzzle, current puzzle.Puzzle) (puzzle.Puzzl
 	return puzzle.Puzzle{}, false
 }
 
+// nextUnsolvedPuzzle returns the first unsolved puzzle in an unlocked level
+// after current, wrapping around to the start of the list.
+func nextUnsolvedPuzzle(all []puzzle.Puzzle, prog *progress.Store, current puzzle.Puzzle) (puzzle.Puzzle, bool) {
+	index := -1
+	for i, p := range all {
+		if p.ID == current.ID {
+			index = i
+			break
+		}
+	}
+
+	for i := 1; i <= len(all); i++ {
+		p := all[(index+i)%len(all)]
+		if p.ID == current.ID {
+			continue
+		}
+		if prog.GetBest(p.ID).Stars < puzzle.OneStar && prog.IsLevelUnlocked(p.Level, all) {
+			return p, true
+		}
+	}
+	return puzzle.Puzzle{}, false
+}
+
+// levelTrackView is the app's track list with the cursor on current's level.
+func (a App) levelTrackView(current puzzle.Puzzle) TrackView {
+	tv := trackViewForLevel(a.puzzles, a.progress, current)
+	tv.width = a.width
+	tv.height = a.height
+	tv.puzzleSet = a.puzzleSet
+	return tv
+}
+
 func trackViewForLevel(all []puzzle.Puzzle, prog *progress.Store, current puzzle.Puzzle) TrackView {
 	tv := NewTrackView(all, prog)
 	tv.cursor = 0
@@ -173,15 +529,21 @@ func trackViewForLevel(all []puzzle.Puzzle, prog *progress.Store, current puzzle
 }
 
 func (a App) View() string {
-	if a.err != nil {
-		return fmt.Sprintf("Error: %v\n\nPress Ctrl+C to exit.", a.err)
-	}
-
 	switch a.screen {
 	case screenTrack:
 		return a.trackView.View()
 	case screenPuzzle:
 		return a.puzzleView.View()
+	case screenHelp:
+		return a.renderHelp()
+	case screenSettings:
+		return a.settingsView.View()
+	case screenError:
+		return a.renderError()
+	case screenStats:
+		return a.renderStats()
+	case screenTutorial:
+		return a.tutorial.View()
 	}
 
 	return ""
@@ -0,0 +1,344 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	nvimclient "github.com/vimgym/vimgym/internal/nvim"
+	"github.com/vimgym/vimgym/internal/progress"
+	"github.com/vimgym/vimgym/internal/puzzle"
+)
+
+func TestNextUnsolvedPuzzle(t *testing.T) {
+	all := []puzzle.Puzzle{
+		{ID: "a", Level: 1}, {ID: "b", Level: 1}, {ID: "c", Level: 1},
+		{ID: "d", Level: 2},
+	}
+	prog, err := progress.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog.SetBest("b", puzzle.ThreeStar, 3, nil)
+
+	// Already-solved b is skipped; d stays locked while a and c are unsolved.
+	if p, ok := nextUnsolvedPuzzle(all, prog, all[0]); !ok || p.ID != "c" {
+		t.Errorf("after a: got %q, %v; want c", p.ID, ok)
+	}
+	// Wraps around to a after the last unlocked puzzle.
+	if p, ok := nextUnsolvedPuzzle(all, prog, all[2]); !ok || p.ID != "a" {
+		t.Errorf("after c: got %q, %v; want a", p.ID, ok)
+	}
+
+	prog.SetBest("a", puzzle.OneStar, 9, nil)
+	prog.SetBest("c", puzzle.TwoStar, 5, nil)
+	if p, ok := nextUnsolvedPuzzle(all, prog, all[2]); !ok || p.ID != "d" {
+		t.Errorf("after unlocking level 2: got %q, %v; want d", p.ID, ok)
+	}
+
+	prog.SetBest("d", puzzle.OneStar, 9, nil)
+	if p, ok := nextUnsolvedPuzzle(all, prog, all[3]); ok {
+		t.Errorf("all solved: got %q, want none", p.ID)
+	}
+}
+
+func TestHelpOverlay(t *testing.T) {
+	all := []puzzle.Puzzle{{ID: "a", Level: 1}}
+	prog, err := progress.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := App{screen: screenTrack, puzzles: all, progress: prog, trackView: NewTrackView(all, prog)}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	m, _ := a.Update(key("?"))
+	a = m.(App)
+	if a.screen != screenHelp || !strings.Contains(a.View(), levelTips[1][0]) {
+		t.Fatal("? on the track screen did not open help with level 1 tips")
+	}
+	m, _ = a.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	a = m.(App)
+	if a.screen != screenTrack {
+		t.Fatal("esc did not close help")
+	}
+
+	// While solving, ? belongs to Vim; F1 still opens help.
+	a.screen = screenPuzzle
+	a.puzzleView = NewPuzzleView(all[0], nil, nil, all)
+	m, _ = a.Update(key("?"))
+	if m.(App).screen != screenPuzzle {
+		t.Error("? opened help while solving")
+	}
+	m, _ = a.Update(tea.KeyMsg{Type: tea.KeyF1})
+	if m.(App).screen != screenHelp || m.(App).helpReturn != screenPuzzle {
+		t.Error("F1 did not open help over the puzzle")
+	}
+}
+
+func TestNvimStartupErrorScreen(t *testing.T) {
+	all := []puzzle.Puzzle{{ID: "a", Level: 1}}
+	prog, err := progress.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	defer func(orig func() (*nvimclient.Client, error)) { newNvim = orig }(newNvim)
+	newNvim = func() (*nvimclient.Client, error) {
+		calls++
+		return nil, fmt.Errorf("%w: exec: not found", nvimclient.ErrNvimNotFound)
+	}
+	a := App{screen: screenTrack, puzzles: all, progress: prog, trackView: NewTrackView(all, prog)}
+
+	m, _ := a.Update(selectedPuzzle{puzzle: all[0]})
+	a = m.(App)
+	if a.screen != screenError || !strings.Contains(a.View(), "not installed") || !strings.Contains(a.View(), "exec: not found") {
+		t.Fatalf("startup failure did not show the error screen:\n%s", a.View())
+	}
+
+	m, _ = a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	a = m.(App)
+	if calls != 2 || a.screen != screenError {
+		t.Errorf("retry: %d start attempts, screen %v; want 2 attempts, still on the error screen", calls, a.screen)
+	}
+
+	m, _ = a.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.(App).screen != screenTrack {
+		t.Error("esc did not return to the track list")
+	}
+}
+
+func TestRestartHungNvim(t *testing.T) {
+	all := []puzzle.Puzzle{{ID: "a", Level: 1}}
+	prog, err := progress.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(orig func() (*nvimclient.Client, error)) { newNvim = orig }(newNvim)
+	fresh := &nvimclient.Client{}
+	newNvim = func() (*nvimclient.Client, error) { return fresh, nil }
+
+	a := App{screen: screenPuzzle, puzzles: all, progress: prog, nvim: &nvimclient.Client{}}
+	a.puzzleView = a.newPuzzleView(all[0])
+	m, _ := a.Update(nvimRestartMsg{})
+	a = m.(App)
+	if a.nvim != fresh || a.puzzleView.nvim != fresh || a.screen != screenPuzzle {
+		t.Error("restart did not replace Neovim and reopen the puzzle")
+	}
+}
+
+func TestStatsTrend(t *testing.T) {
+	all := []puzzle.Puzzle{{ID: "a", Track: 1, Level: 1, Title: "A"}}
+	prog, err := progress.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := App{screen: screenStats, puzzles: all, progress: prog, trackView: NewTrackView(all, prog)}
+	if view := a.View(); strings.Contains(view, "Trend:") {
+		t.Errorf("trend shown with an empty history:\n%s", view)
+	}
+
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	for i := range trendSolves + 5 {
+		// Alternate puzzles so no single history reaches its own cap.
+		id := []string{"a", "b"}[i%2]
+		prog.RecordSolve(id, progress.Attempt{At: start.Add(time.Duration(i) * time.Minute), Keystrokes: 2 + i, Par: 2})
+	}
+	// Only the latest solves are drawn: 5 through 24 keys over par.
+	values := make([]int, trendSolves)
+	for i := range values {
+		values[i] = 5 + i
+	}
+	want := fmt.Sprintf("Trend: %s (keys over par, last %d)", sparkline(values), trendSolves)
+	if view := a.View(); !strings.Contains(view, want) {
+		t.Errorf("stats screen lacks %q:\n%s", want, view)
+	}
+	if got := sparkline([]int{5, 6, 7, 12}); got != "▁▂▃█" {
+		t.Errorf("sparkline = %q, want ▁▂▃█", got)
+	}
+	if got := sparkline([]int{3, 3}); got != "▁▁" {
+		t.Errorf("sparkline of equal values = %q, want ▁▁", got)
+	}
+	if strings.Contains(a.trackView.View(), "Trend:") {
+		t.Error("trend still drawn in the level list")
+	}
+}
+
+func TestStatsScreen(t *testing.T) {
+	all := []puzzle.Puzzle{{ID: "a", Track: 1, Level: 1, Title: "A"}, {ID: "b", Track: 1, Level: 1, Title: "B"}}
+	prog, err := progress.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog.SetBest("a", puzzle.ThreeStar, 5, nil)
+	a := App{screen: screenTrack, puzzles: all, progress: prog, trackView: NewTrackView(all, prog)}
+
+	m, cmd := a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	m, _ = m.(App).Update(cmd())
+	a = m.(App)
+	view := a.View()
+	if a.screen != screenStats || !strings.Contains(view, "1/2 (50%)") || !strings.Contains(view, "Average stars: 3.0") {
+		t.Fatalf("stats screen not shown or wrong:\n%s", view)
+	}
+	if !strings.Contains(view, "Hardest unsolved: Lv 1") {
+		t.Error("stats screen lacks the hardest unsolved level")
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	m, _ = a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	a = m.(App)
+	path, ok := strings.CutPrefix(a.statsStatus, "Progress exported to ")
+	if !ok || !strings.HasSuffix(path, ".csv") {
+		t.Fatalf("unexpected export status %q", a.statsStatus)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("export file not written: %v", err)
+	}
+
+	m, _ = a.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.(App).screen != screenTrack {
+		t.Error("esc did not close the stats screen")
+	}
+}
+
+func TestFirstRunTutorial(t *testing.T) {
+	t.Setenv(progress.DataDirEnv, t.TempDir())
+	defer func(orig func() (*nvimclient.Client, error)) { newNvim = orig }(newNvim)
+	newNvim = func() (*nvimclient.Client, error) { return &nvimclient.Client{}, nil }
+	all := []puzzle.Puzzle{{ID: "a", Track: 1, Level: 1, Title: "A"}}
+
+	app, err := NewApp(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.screen != screenTutorial || !strings.Contains(app.View(), "Your First Edit") {
+		t.Fatalf("first run did not open the tutorial:\n%s", app.View())
+	}
+
+	m, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = m.(App).Update(cmd())
+	a := m.(App)
+	if a.screen != screenPuzzle || a.puzzleView.puzzle.ID != "tutorial-01" || a.puzzleView.progress != nil {
+		t.Fatalf("enter opened %q on screen %v; want the first tutorial puzzle without progress", a.puzzleView.puzzle.ID, a.screen)
+	}
+
+	a.puzzleView.state = stateCleared
+	m, _ = a.Update(puzzleExitMsg{next: true})
+	a = m.(App)
+	if a.screen != screenTutorial || !a.tutorial.cleared[0] || a.tutorial.cursor != 1 {
+		t.Fatalf("clearing a tutorial puzzle: screen %v, cleared %v, cursor %d", a.screen, a.tutorial.cleared, a.tutorial.cursor)
+	}
+	if !a.progress.IsEmpty() {
+		t.Error("a tutorial puzzle was recorded as progress")
+	}
+
+	m, cmd = a.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m, _ = m.(App).Update(cmd())
+	if m.(App).screen != screenTrack {
+		t.Fatal("esc did not skip to the track list")
+	}
+
+	app, err = NewApp(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.screen != screenTrack {
+		t.Error("the tutorial showed again after being skipped")
+	}
+}
+
+func TestReplayTutorialFromSettings(t *testing.T) {
+	t.Setenv(progress.DataDirEnv, t.TempDir())
+	all := []puzzle.Puzzle{{ID: "a", Track: 1, Level: 1, Title: "A"}}
+	app, err := NewApp(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.settings.TutorialDone = true
+	app.screen = screenSettings
+	app.settingsView = NewSettingsView(*app.settings)
+
+	a := *app
+	for !settingOptions[a.settingsView.cursor].action {
+		m, _ := a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+		a = m.(App)
+	}
+	m, cmd := a.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = m.(App).Update(cmd())
+	a = m.(App)
+	if a.screen != screenTutorial || !a.inTutorial || a.tutorial.cursor != 0 {
+		t.Errorf("replay tutorial: screen %v, inTutorial %v", a.screen, a.inTutorial)
+	}
+}
+
+func TestStartInPuzzleOrLevel(t *testing.T) {
+	t.Setenv(progress.DataDirEnv, t.TempDir())
+	defer func(orig func() (*nvimclient.Client, error)) { newNvim = orig }(newNvim)
+	newNvim = func() (*nvimclient.Client, error) { return &nvimclient.Client{}, nil }
+	all := []puzzle.Puzzle{
+		{ID: "a", Track: 1, Level: 1, Title: "A"},
+		{ID: "b", Track: 1, Level: 2, Title: "B"},
+	}
+
+	app, err := NewApp(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.OpenPuzzle("nope"); err == nil || !strings.Contains(err.Error(), `"nope"`) {
+		t.Errorf("unknown ID: got %v, want an error naming it", err)
+	}
+	if err := app.OpenPuzzle("b"); err != nil {
+		t.Fatal(err)
+	}
+	m, _ := app.Update(app.Init()())
+	a := m.(App)
+	if a.screen != screenPuzzle || a.puzzleView.puzzle.ID != "b" {
+		t.Fatalf("started on screen %v in %q, want puzzle b", a.screen, a.puzzleView.puzzle.ID)
+	}
+	m, _ = a.Update(puzzleExitMsg{})
+	a = m.(App)
+	if a.screen != screenTrack || a.trackView.allLevels[a.trackView.cursor].level != 2 {
+		t.Errorf("leaving the puzzle: screen %v, want the track list on level 2", a.screen)
+	}
+
+	app, err = NewApp(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.OpenLevel(3); err == nil {
+		t.Error("OpenLevel(3) succeeded for a missing level")
+	}
+	if err := app.OpenLevel(2); err != nil {
+		t.Fatal(err)
+	}
+	if app.screen != screenTrack || app.trackView.mode != viewPuzzles || app.trackView.listLevel != 2 || app.Init() != nil {
+		t.Errorf("OpenLevel(2): screen %v, mode %v, level %d", app.screen, app.trackView.mode, app.trackView.listLevel)
+	}
+}
+
+func TestPuzzleSetShown(t *testing.T) {
+	t.Setenv(progress.DataDirEnv, t.TempDir())
+	all := []puzzle.Puzzle{{ID: "a", Track: 1, Level: 1, Title: "A"}}
+	app, err := NewApp(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, _ := app.Update(tutorialDoneMsg{})
+	*app = m.(App)
+	if strings.Contains(app.View(), "Puzzles:") {
+		t.Error("the built-in set should not be labeled")
+	}
+
+	app.SetPuzzleSet("mine.json (instead of the built-in puzzles)")
+	if !strings.Contains(app.View(), "Puzzles: mine.json") {
+		t.Fatalf("level list does not name the puzzle set:\n%s", app.View())
+	}
+	app.screen = screenPuzzle
+	app.puzzleView = NewPuzzleView(all[0], nil, nil, all)
+	m, _ = app.Update(puzzleExitMsg{})
+	if !strings.Contains(m.(App).View(), "Puzzles: mine.json") {
+		t.Error("the puzzle set label was lost after leaving a puzzle")
+	}
+}
@@ -0,0 +1,18 @@
+package tui
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+)
+
+// clipboardOut receives OSC 52 clipboard sequences (replaced in tests).
+var clipboardOut io.Writer = os.Stdout
+
+// copyToClipboard asks the terminal to put text on the system clipboard using
+// the OSC 52 escape sequence. Terminals without OSC 52 support ignore it.
+func copyToClipboard(text string) error {
+	_, err := fmt.Fprintf(clipboardOut, "\x1b]52;c;%s\x07", base64.StdEncoding.EncodeToString([]byte(text)))
+	return err
+}
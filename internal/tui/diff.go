@@ -0,0 +1,142 @@
+package tui
+
+import "strings"
+
+// diffOp is how a line fares between two texts.
+type diffOp int
+
+const (
+	diffEqual diffOp = iota
+	diffDelete
+	diffInsert
+)
+
+// lineDiff is one line of a line-level diff.
+type lineDiff struct {
+	op   diffOp
+	text string
+}
+
+// diffLines returns a shortest line diff turning a into b, from a longest
+// common subsequence. Puzzle texts are small, so the quadratic table is fine.
+func diffLines(a, b []string) []lineDiff {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []lineDiff
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, lineDiff{diffEqual, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, lineDiff{diffDelete, a[i]})
+			i++
+		default:
+			out = append(out, lineDiff{diffInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out = append(out, lineDiff{diffDelete, a[i]})
+	}
+	for ; j < len(b); j++ {
+		out = append(out, lineDiff{diffInsert, b[j]})
+	}
+	return out
+}
+
+// commonAffixes returns the lengths of the common prefix and, in what is
+// left, the common suffix of a and b.
+func commonAffixes(a, b []rune) (prefix, suffix int) {
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	return prefix, suffix
+}
+
+// renderCharDiff renders a changed line in one row: the unchanged prefix and
+// suffix as is, with the removed runes struck out in red before the added
+// runes in green.
+func renderCharDiff(before, after string) string {
+	a, b := []rune(before), []rune(after)
+	prefix, suffix := commonAffixes(a, b)
+	var s strings.Builder
+	s.WriteString(string(a[:prefix]))
+	if removed := string(a[prefix : len(a)-suffix]); removed != "" {
+		s.WriteString(diffDeleteStyle.Render(removed))
+	}
+	if added := string(b[prefix : len(b)-suffix]); added != "" {
+		s.WriteString(diffInsertStyle.Render(added))
+	}
+	s.WriteString(string(a[len(a)-suffix:]))
+	return s.String()
+}
+
+// diffRows renders a line diff one row per line. A run of deleted lines
+// followed by as many inserted lines is shown as changed lines (~), diffed
+// by character; other deletions (-) and insertions (+) are shown whole. It
+// also returns the first changed row.
+func diffRows(ops []lineDiff) ([]string, int) {
+	var rows []string
+	first := -1
+	for i := 0; i < len(ops); {
+		if ops[i].op == diffEqual {
+			rows = append(rows, "  "+ops[i].text)
+			i++
+			continue
+		}
+		if first < 0 {
+			first = len(rows)
+		}
+		dels := i
+		for dels < len(ops) && ops[dels].op == diffDelete {
+			dels++
+		}
+		ins := dels
+		for ins < len(ops) && ops[ins].op == diffInsert {
+			ins++
+		}
+		if n := dels - i; n > 0 && ins-dels == n {
+			for k := 0; k < n; k++ {
+				rows = append(rows, mutedStyle.Render("~ ")+renderCharDiff(ops[i+k].text, ops[dels+k].text))
+			}
+		} else {
+			for _, op := range ops[i:dels] {
+				rows = append(rows, diffDeleteStyle.Render("- "+op.text))
+			}
+			for _, op := range ops[dels:ins] {
+				rows = append(rows, diffInsertStyle.Render("+ "+op.text))
+			}
+		}
+		i = ins
+	}
+	return rows, max(first, 0)
+}
+
+// renderTextDiff shows what changes from before to after in at most height
+// rows, windowed to start just above the first change.
+func renderTextDiff(before, after string, height int) string {
+	rows, first := diffRows(diffLines(strings.Split(before, "\n"), strings.Split(after, "\n")))
+	height = max(height, 1)
+	end := min(len(rows), max(first-1, 0)+height)
+	start := max(0, end-height)
+	return strings.Join(rows[start:end], "\n")
+}
@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	got := diffLines([]string{"a", "b", "c", "d"}, []string{"a", "x", "c", "d", "e"})
+	want := []lineDiff{
+		{diffEqual, "a"}, {diffDelete, "b"}, {diffInsert, "x"},
+		{diffEqual, "c"}, {diffEqual, "d"}, {diffInsert, "e"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffLines = %v, want %v", got, want)
+	}
+}
+
+func TestRenderTextDiff(t *testing.T) {
+	// Without a color profile the styles render as plain text.
+	tests := []struct {
+		name, before, after string
+		height              int
+		want                []string
+	}{
+		{"changed line", "hello world", "world", 6, []string{"~ hello world"}},
+		{"added line", "a\nb", "a\nx\nb", 6, []string{"  a", "+ x", "  b"}},
+		{"deleted lines", "a\nb\nc", "a", 6, []string{"  a", "- b", "- c"}},
+		{
+			"windowed around the change",
+			"1\n2\n3\n4\n5\n6\n7\n8", "1\n2\n3\n4\n5\nsix\n7\n8", 3,
+			[]string{"  5", "~ 6six", "  7"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Split(renderTextDiff(tt.before, tt.after, tt.height), "\n")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("renderTextDiff = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommonAffixes(t *testing.T) {
+	tests := []struct {
+		a, b           string
+		prefix, suffix int
+	}{
+		{"abc", "abc", 3, 0},
+		{"abXc", "abc", 2, 1},
+		{"aa", "aaa", 2, 0},
+		{"日本語", "日語", 1, 1},
+	}
+	for _, tt := range tests {
+		p, s := commonAffixes([]rune(tt.a), []rune(tt.b))
+		if p != tt.prefix || s != tt.suffix {
+			t.Errorf("commonAffixes(%q, %q) = %d, %d; want %d, %d", tt.a, tt.b, p, s, tt.prefix, tt.suffix)
+		}
+	}
+}
+
+func TestClearScreenShowsDiff(t *testing.T) {
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+	v.lines = []string{"world"}
+	v.state = stateCleared
+	if view := v.View(); !strings.Contains(view, "CHANGES") || !strings.Contains(view, "~ hello world") {
+		t.Errorf("clear screen has no before/after diff:\n%s", view)
+	}
+}
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vimgym/vimgym/internal/puzzle"
+)
+
+// galleryPreviewLines is the most text lines shown per side in the gallery.
+const galleryPreviewLines = 6
+
+// renderGallery shows the selected puzzle's before and after text side by side,
+// without loading it into Neovim.
+func (v TrackView) renderGallery(width, height int) string {
+	if len(v.puzzleList) == 0 {
+		return mutedStyle.Render("  No puzzles.")
+	}
+	p := v.puzzleList[v.cursor]
+
+	header := titleStyle.MaxWidth(width).Render(fmt.Sprintf("Gallery %d/%d", v.cursor+1, len(v.puzzleList)))
+	info := fmt.Sprintf("%s  %s", selectedStyle.Render(p.Title), mutedStyle.Render(fmt.Sprintf("Track %d · Lv %d · par %d", p.Track, p.Level, p.Par)))
+	if !v.progress.IsLevelUnlocked(p.Level, v.puzzles) {
+		info += lockedStyle.Render(" [locked]")
+	} else if r := v.progress.GetBest(p.ID); r.Stars > puzzle.NoStar {
+		info += "  " + FormatStars(int(r.Stars)) + PerfectBadge(r.Perfect)
+	}
+
+	// Two boxes plus " → " between them; each box has a border and padding.
+	boxWidth := max(8, (width-3)/2-4)
+	// Header, info, box borders, labels and footer take about ten rows.
+	rows := max(1, min(galleryPreviewLines, height-10))
+	before, after := galleryPreview(p, boxWidth, rows)
+	preview := lipgloss.JoinHorizontal(lipgloss.Top,
+		editorBoxStyle.Width(boxWidth).Render(labelStyle.Render("Before")+"\n"+before),
+		" → ",
+		goalBoxStyle.Width(boxWidth).Render(labelStyle.Render("After")+"\n"+after),
+	)
+
+	helpLine := "  j/k: browse  enter: start  esc: back"
+	footer := helpStyle.MaxWidth(width).Render(helpLine) + v.footerExtras(width)
+
+	return strings.Join([]string{header, fitWidth(info, width), "", preview, footer}, "\n")
+}
+
+// galleryPreview returns compact before and after text windows, both centered
+// on the first line the puzzle changes.
+func galleryPreview(p puzzle.Puzzle, width, rows int) (string, string) {
+	beforeLines := strings.Split(p.Before.Text, "\n")
+	afterLines := strings.Split(p.After.Text, "\n")
+	focus := goalFocusRow(beforeLines, afterLines)
+	return previewWindow(beforeLines, focus, width, rows), previewWindow(afterLines, focus, width, rows)
+}
+
+func previewWindow(lines []string, focus, width, rows int) string {
+	if len(lines) == 1 && lines[0] == "" {
+		return mutedStyle.Render("(empty buffer)")
+	}
+	start, end := windowRange(len(lines), min(focus, len(lines)-1), rows)
+	shown := make([]string, 0, end-start)
+	for _, l := range lines[start:end] {
+		shown = append(shown, truncateLine(l, width))
+	}
+	return strings.Join(shown, "\n")
+}
@@ -0,0 +1,54 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/vimgym/vimgym/internal/progress"
+	"github.com/vimgym/vimgym/internal/puzzle"
+)
+
+func TestGalleryPreviewFocusesOnChange(t *testing.T) {
+	p := puzzle.Puzzle{
+		Before: puzzle.BeforeState{Text: "1\n2\n3\n4\n5\n6\n7\n8\nold"},
+		After:  puzzle.AfterState{Text: "1\n2\n3\n4\n5\n6\n7\n8\nnew"},
+	}
+	before, after := galleryPreview(p, 20, 3)
+	if !strings.Contains(before, "old") || !strings.Contains(after, "new") {
+		t.Errorf("preview does not show the changed line: %q / %q", before, after)
+	}
+	if strings.Contains(after, "1") {
+		t.Errorf("preview window not limited to 3 rows around the change: %q", after)
+	}
+}
+
+func TestGallerySelectsOnlyUnlockedPuzzles(t *testing.T) {
+	all := []puzzle.Puzzle{
+		{ID: "a", Title: "A", Level: 1, Par: 1},
+		{ID: "b", Title: "B", Level: 2, Par: 1},
+	}
+	prog, err := progress.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewTrackView(all, prog)
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	if v.mode != viewGallery || v.View() == "" {
+		t.Fatal("g did not open the gallery")
+	}
+
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if _, cmd := v.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Error("locked puzzle started from the gallery")
+	}
+
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+	_, cmd := v.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("enter did not start the unlocked puzzle")
+	}
+	if sel, ok := cmd().(selectedPuzzle); !ok || sel.puzzle.ID != "a" {
+		t.Errorf("selected %+v, want puzzle a", sel)
+	}
+}
@@ -0,0 +1,167 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// levelTips are short reminders of the Vim commands each level practices.
+var levelTips = map[int][]string{
+	1:  {"h j k l move left, down, up, right", "A count repeats a motion: 5j moves down five lines"},
+	2:  {"w / b jump to the next / previous word start", "e jumps to the end of a word; W B E treat punctuation as part of a WORD"},
+	3:  {"0 goes to column 0, ^ to the first non-blank, $ to the line end", "gg and G jump to the first and last line; 5G to line 5"},
+	4:  {"{ and } jump between paragraphs (blank lines)", "% jumps to the matching bracket"},
+	5:  {"f/F{char} find a character forward/backward on the line", "t/T{char} stop just before it; ; and , repeat the find"},
+	6:  {"i a insert before/after the cursor; I A at line start/end", "o O open a new line below/above"},
+	7:  {"x deletes a character, dd a line, D to the end of the line", "A count works here too: 3dd, 4x"},
+	8:  {"d{motion} deletes over any motion: dw, d$, dj, dfx", "Counts can go before or after the operator: 2dw or d2w"},
+	9:  {"c{motion} deletes and enters insert mode: cw, c$, cc", "s and S are shorthands for cl and cc"},
+	10: {"y{motion} yanks (copies); yy yanks a line", "p P put after/before the cursor"},
+	11: {"iw aw, i\" a\", i( a( select inside/around an object", "Combine with any operator: ciw, da(, yi\""},
+	12: {"v V Ctrl-V start charwise, linewise, block visual mode", "Text objects work in visual mode too: viw, vap"},
+	13: {"/pattern searches forward, ?pattern backward", "n and N repeat the search; * and # search the word under the cursor"},
+	14: {":s/old/new/ substitutes on the line; add g for every match", ":%s applies to the whole file"},
+	15: {". repeats the last change", "qa records a macro into a, q stops, @a replays, @@ repeats"},
+	16: {"Chain motions and operators: count + operator + motion", "Think in sentences: d3w, c2j, y$"},
+	17: {"Text objects let you act without moving first", "ci( and da\" work from anywhere inside the pair"},
+	18: {"\"ayy yanks into register a; \"ap puts it", "\"0 holds the last yank even after deletes"},
+	19: {"ma sets mark a; 'a jumps to its line, `a to its exact spot", "'' and `` jump back to where you came from"},
+	20: {"\\( \\) capture groups; \\1 reuses them in the replacement", "\\v very magic mode avoids most backslashes"},
+	21: {"Ctrl-V then I or A edits every line of the block", "$ in block mode extends to each line's end"},
+	22: {">> and << shift lines; = reindents", "gq formats text to the text width; J joins lines"},
+	23: {"Macros can call counts: 10@a", "End a macro on a motion to the next target so replays chain"},
+	24: {":g/pattern/cmd runs a command on every matching line", ":normal runs normal-mode keys on a range"},
+	25: {"* then cgn changes the next match; . repeats on the following one", "Combine search, text objects and . for refactors"},
+	26: {"Look for the fewest keys: counts, text objects, and .", "Plan the whole edit before you start typing"},
+	27: {"Speed comes from reaching for the biggest motion", "Avoid repeating h j k l when w, f or a search gets there"},
+	28: {"Macros and :g turn one edit into many", "Visual block inserts are quick for columns"},
+	29: {"Ctrl-A and Ctrl-X increment and decrement numbers", "g~ gu gU change case over a motion"},
+	30: {"Everything together: pick the shortest path", "Golf: every key counts"},
+}
+
+// helpLevel returns the level whose tips the help screen should show, or 0.
+func (a App) helpLevel() int {
+	if a.helpReturn == screenPuzzle {
+		return a.puzzleView.puzzle.Level
+	}
+	tv := a.trackView
+	switch tv.mode {
+	case viewLevels:
+		if tv.cursor < len(tv.allLevels) {
+			return tv.allLevels[tv.cursor].level
+		}
+	case viewPuzzles:
+		return tv.listLevel
+	}
+	return 0
+}
+
+// helpLines builds the cheatsheet: tips for level, then key bindings.
+func helpLines(level int) []string {
+	section := func(title string) string { return labelStyle.Render(title) }
+	entry := func(key, desc string) string {
+		return fmt.Sprintf("  %s  %s", selectedStyle.Render(fmt.Sprintf("%-9s", key)), desc)
+	}
+	var lines []string
+	if tips := levelTips[level]; len(tips) > 0 {
+		title := fmt.Sprintf("Level %d", level)
+		if desc := levelDescriptions[level]; desc != "" {
+			title += ": " + desc
+		}
+		lines = append(lines, section(title))
+		for _, tip := range tips {
+			lines = append(lines, "  • "+tip)
+		}
+		lines = append(lines, "")
+	}
+	lines = append(lines,
+		section("Menus"),
+		entry("j / k", "move"),
+		entry("enter", "open level / start puzzle"),
+		entry("esc", "back"),
+		entry("/", "filter the list by title or category"),
+		entry("0-9", "type a level number to jump to it"),
+		entry("d", "puzzle list: sort by difficulty / original order"),
+		entry("n", "play the recommended puzzle"),
+		entry("r", "random unsolved puzzle"),
+		entry("v", "review queue"),
+		entry("F", "favorite puzzles (f on a puzzle list toggles one)"),
+		entry("g", "puzzle gallery"),
+		entry("A", "export analytics"),
+		entry("i", "statistics (e/c: export JSON/CSV)"),
+		entry("s", "sandbox: free practice, no goal or score"),
+		entry("S", "settings"),
+		entry("Ctrl+R", "reset all progress"),
+		"",
+		section("While solving"),
+		entry("Ctrl+H", "toggle hint"),
+		entry("Ctrl+O", "toggle optimal solution (space: step through)"),
+		entry("F3", "reveal the next solution key"),
+		entry("F2", "word/char counts"),
+		entry("F4", "show your keys"),
+		entry("F6", "ghost diff: dim lines that differ from the goal"),
+		entry("F5", "reset puzzle (or to the last checkpoint)"),
+		entry("u / Ctrl+R", "Vim's undo and redo; neither counts as a keystroke"),
+		entry("Ctrl+Q", "quit to level select"),
+		entry("F1", "this help (? is Vim's backward search here)"),
+		"",
+		section("After clearing"),
+		entry("enter", "next puzzle"),
+		entry("n", "next unsolved puzzle"),
+		entry("r", "retry"),
+		entry("y", "copy the optimal solution"),
+		entry("d", "demo: watch the optimal solution play (not scored)"),
+		entry("q", "back"),
+	)
+	return lines
+}
+
+// helpSize returns the terminal size used for the help overlay.
+func (a App) helpSize() (int, int) {
+	width, height := a.width, a.height
+	if width <= 0 {
+		width = 80
+	}
+	if height <= 0 {
+		height = 24
+	}
+	return width, height
+}
+
+// helpChromeLines is the height of the help title, footer and spacing.
+const helpChromeLines = 6
+
+// maxHelpScroll returns the largest useful scroll offset for the help overlay.
+func (a App) maxHelpScroll() int {
+	_, height := a.helpSize()
+	return max(0, len(helpLines(a.helpLevel()))-max(1, height-helpChromeLines))
+}
+
+// renderHelp draws the help overlay, scrolled by a.helpScroll.
+func (a App) renderHelp() string {
+	width, height := a.helpSize()
+	title := "VimGym - Help"
+	if a.nvim != nil {
+		title += fmt.Sprintf("  (Neovim %s)", a.nvim.Version())
+	}
+	header := titleStyle.MaxWidth(width).Render(title)
+	footer := helpStyle.MaxWidth(width).Render("  j/k: scroll  esc/?: close")
+	lines := helpLines(a.helpLevel())
+
+	available := max(1, height-helpChromeLines)
+	scroll := min(a.helpScroll, a.maxHelpScroll())
+	end := min(len(lines), scroll+available)
+
+	var b strings.Builder
+	b.WriteString(header)
+	b.WriteString("\n\n")
+	for i, line := range lines[scroll:end] {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(fitWidth(line, width))
+	}
+	b.WriteString("\n\n")
+	b.WriteString(footer)
+	return b.String()
+}
@@ -15,3 +15,20 @@ func overallProgressText(prog *progress.Store, puzzles []puzzle.Puzzle) string {
 	solved, total, percent := prog.OverallProgress(puzzles)
 	return fmt.Sprintf("Progress: %d/%d (%d%%)", solved, total, percent)
 }
+
+// streakText describes the daily practice streak, or "" before any solve.
+func streakText(prog *progress.Store) string {
+	if prog == nil {
+		return ""
+	}
+	longest := prog.LongestStreak()
+	if longest == 0 {
+		return ""
+	}
+	current := prog.CurrentStreak()
+	unit := "days"
+	if current == 1 {
+		unit = "day"
+	}
+	return fmt.Sprintf("Streak: %d %s (best %d)", current, unit, longest)
+}
@@ -1,7 +1,9 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
+	"math/rand/v2"
 	"os"
 	"reflect"
 	"strconv"
@@ -18,23 +20,57 @@ import (
 
 var debugKeysEnabled = os.Getenv("VIMGYM_DEBUG_KEYS") != ""
 
+// maxKeyLog caps the number of commands kept in the key log.
+const maxKeyLog = 200
+
+// clearDiffLines caps the before/after diff shown on the clear screen.
+const clearDiffLines = 6
+
+// solutionsDisabled hides the optimal solution from reveal and copy actions,
+// for challenge runs without assistance.
+var solutionsDisabled = os.Getenv("VIMGYM_NO_SOLUTIONS") != ""
+
 type puzzleState int
 
 const (
 	statePlaying puzzleState = iota
 	stateCleared
+	// stateDemo plays the optimal solution back in Neovim, unscored.
+	stateDemo
 )
 
 // puzzleExitMsg is sent when leaving puzzle view.
 type puzzleExitMsg struct {
 	next bool
+	// nextUnsolved skips ahead to the next unsolved puzzle in any unlocked level.
+	nextUnsolved bool
+}
+
+// nvimInputter receives the keys the puzzle view forwards to Neovim.
+type nvimInputter interface {
+	Input(keys string) error
+}
+
+// NvimController is the part of the Neovim client the puzzle view drives.
+// *nvim.Client implements it; tests substitute a fake.
+type NvimController interface {
+	nvimInputter
+	GetState() (lines []string, row, col int, mode string, err error)
+	GetRegister(name string) (string, error)
+	GetVisualSelection() (int, int, int, int, error)
+	IsRecording() (string, error)
+	LoadPuzzle(p puzzle.Puzzle) error
+	ResizeUI(width, height int)
+	Ping(timeout time.Duration) error
+	LiveUpdates() bool
+	Close() error
 }
 
 // PuzzleView handles the puzzle solving screen.
 type PuzzleView struct {
 	puzzle     puzzle.Puzzle
 	allPuzzles []puzzle.Puzzle
-	nvim       *nvimclient.Client
+	nvim       NvimController
 	progress   *progress.Store
 	state      puzzleState
 
@@ -46,25 +82,101 @@ type PuzzleView struct {
 	cursorCol  int
 	showHint   bool
 	showSolution bool
+	showCounts bool
+	// strictDiverged is set when a StrictPrefix puzzle's buffer has left the goal prefix.
+	strictDiverged bool
+	// cursorMismatch is set when the text matches but AfterCursor does not.
+	cursorMismatch bool
+	// elapsed is the accumulated solving time; timerStart is zero while paused.
+	elapsed    time.Duration
+	timerStart time.Time
+	// timerStarted is set by the first keystroke of an attempt.
+	timerStarted bool
+	// clockID identifies the live clock ticker; stale ticks are dropped.
+	clockID int
+	// keyLog holds the commands sent to Neovim this attempt; buffered
+	// sequences appear once as a whole (e.g. "dw").
+	keyLog     []string
+	showKeyLog bool
+	// ghost dims buffer lines that still differ from the goal and marks the
+	// columns that differ.
+	ghost bool
+	// revealedSteps is how many optimal-solution keys were revealed one at a time.
+	revealedSteps int
+	// solutionViewed is set once the full solution overlay was opened.
+	solutionViewed bool
+	// hintUsed is set once the hint was shown this attempt.
+	hintUsed bool
+	// demoSteps are the commands the demo plays; demoStep is how many have
+	// been sent, and demoID identifies the running demo.
+	demoSteps []solutionStep
+	demoStep  int
+	demoID    int
+	// walkthrough is the solution command shown by the step-through (0 = none yet).
+	walkthrough int
+	// selection is the live charwise or linewise visual selection, if any.
+	selection *visualSelection
+	// block is the last visual-block selection; blockInsert is set while a
+	// block insert/append is being typed and blockApplied right after it ends.
+	block        *blockSelection
+	blockInsert  bool
+	blockAppend  bool
+	blockApplied bool
+	// stepIndex is the next intermediate step of a multi-step puzzle;
+	// checkpoint is the state captured when the last step was completed.
+	stepIndex  int
+	checkpoint *checkpoint
+	// goalSeen is set when the last sync matched the goal; the puzzle clears
+	// only if the next sync still matches, so transient states don't count.
+	goalSeen bool
+	// freeUndo makes undo (u) and redo (<C-r>) not count as keystrokes.
+	freeUndo bool
+	// attempt counts attempts in this view, so stale auto-advance ticks from
+	// an earlier clear are ignored after a retry.
+	attempt int
+	// settings are the user's preferences (timer, auto-advance, solutions).
+	settings progress.Settings
 	stars      puzzle.StarRating
+	// perfect is set when the clear matched or beat the optimal solution.
+	perfect bool
+	// copyStatus reports the result of copying the solution on the clear screen.
+	copyStatus string
+	// register is the unnamed register, shown on yank/paste puzzles.
+	register string
+	// recording is the register a macro is being recorded into, if any.
+	recording string
+	// repeatNote describes the last command when it was a dot repeat.
+	repeatNote string
+	// nvimWarning is set while Neovim is not answering in time.
+	nvimWarning string
 	width      int
 	height     int
 	// pendingKeys holds a prefix command waiting for the next key (ex: "r").
 	pendingKeys string
 	// pendingOperator indicates we're waiting for a motion/text object after an operator (d/c/y).
 	pendingOperator bool
-	// pendingNeedsChar indicates the pending keys need one more character (r/f/t/F/T).
+	// pendingNeedsChar indicates the pending keys need one more character
+	// (r/f/t/F/T, a mark after m/`/', or a register after @).
 	pendingNeedsChar bool
 	// pendingTextObject indicates we're waiting for a text object after i/a.
 	pendingTextObject bool
 	// pendingHasCount tracks whether an operator has started a count (e.g. d2w).
 	pendingHasCount bool
-	// pendingCount buffers a leading count before a motion/operator (e.g. 4w, 3dw).
+	// pendingCount buffers a leading count before a motion/operator (e.g. 4w, 3dw),
+	// along with any register prefix (e.g. "a3).
 	pendingCount string
+	// pendingRegister indicates a " is waiting for its register name.
+	pendingRegister bool
+	// syncQueued is set while an nvimSyncMsg is on its way.
+	syncQueued bool
+	// pendingStrokes counts the keystrokes spent on the buffered prefix, so
+	// they can be refunded if it is abandoned.
+	pendingStrokes int
 }
 
 // NewPuzzleView creates a new puzzle view.
-func NewPuzzleView(p puzzle.Puzzle, nv *nvimclient.Client, prog *progress.Store, all []puzzle.Puzzle) PuzzleView {
+// nv may be nil, in which case input goes nowhere.
+func NewPuzzleView(p puzzle.Puzzle, nv NvimController, prog *progress.Store, all []puzzle.Puzzle) PuzzleView {
 	return PuzzleView{
 		puzzle:     p,
 		allPuzzles: all,
@@ -72,12 +184,70 @@ func NewPuzzleView(p puzzle.Puzzle, nv *nvimclient.Client, prog *progress.Store,
 		progress:   prog,
 		state:      statePlaying,
 		mode:       "NORMAL",
+		showCounts: p.ShowCounts,
+		freeUndo:   true,
+		settings:   progress.DefaultSettings(),
 	}
 }
 
 type initPuzzleMsg struct{}
 type nvimSyncMsg struct{}
 
+// checkpoint is the buffer state captured at a completed step.
+type checkpoint struct {
+	before     puzzle.BeforeState
+	keystrokes int
+	keyLog     []string
+	stepIndex  int
+}
+
+// blockSelection is a visual-block rectangle (0-indexed, inclusive).
+type blockSelection struct {
+	startRow, startCol, endRow, endCol int
+}
+
+// visualSelection is a charwise or linewise visual selection (0-indexed rune
+// columns, inclusive), with the start before the end in the buffer.
+type visualSelection struct {
+	startRow, startCol, endRow, endCol int
+	linewise                           bool
+}
+
+// runeCol converts a byte column on a buffer row to a rune column.
+func runeCol(lines []string, row, byteCol int) int {
+	if row < 0 || row >= len(lines) {
+		return byteCol
+	}
+	line := lines[row]
+	return utf8.RuneCountInString(line[:min(max(byteCol, 0), len(line))])
+}
+
+// autoAdvanceMsg moves on from the clear screen when AutoAdvance is on.
+// attempt identifies the clear it was scheduled for.
+type autoAdvanceMsg struct {
+	attempt int
+}
+
+// autoAdvanceDelay is how long the clear screen stays up before auto-advancing.
+const autoAdvanceDelay = 1500 * time.Millisecond
+
+// demoStepMsg plays the next command of the demo; id drops ticks from an
+// earlier demo.
+type demoStepMsg struct {
+	id int
+}
+
+// demoStepDelay is the pause between commands of the demo.
+const demoStepDelay = 900 * time.Millisecond
+
+// clockTickMsg refreshes the live timer once per second.
+type clockTickMsg struct {
+	id int
+}
+
+// clockSeq hands out unique clock IDs so ticks from an old puzzle are ignored.
+var clockSeq int
+
 // Init initializes the puzzle view by loading the puzzle into Neovim.
 func (v PuzzleView) Init() tea.Cmd {
 	return func() tea.Msg {
@@ -85,57 +255,364 @@ func (v PuzzleView) Init() tea.Cmd {
 	}
 }
 
-// syncReadBuffer reads buffer state from Neovim synchronously.
-func (v *PuzzleView) syncReadBuffer() {
+// syncReadBuffer reads buffer state from Neovim synchronously. It reports
+// whether the buffer could be read.
+func (v *PuzzleView) syncReadBuffer() bool {
 	if v.nvim == nil {
-		return
+		return false
 	}
 
-	lines, err := v.nvim.GetLines()
+	lines, row, col, modeStr, err := v.nvim.GetState()
 	if err != nil {
-		return
+		v.noteNvimError(err)
+		return false
 	}
+	v.nvimWarning = ""
 	v.lines = lines
+	if v.puzzle.StrictPrefix {
+		v.strictDiverged = !puzzle.ValidatePrefix(strings.Join(lines, "\n"), v.currentGoal())
+	}
+	v.cursorRow = row
+	v.cursorCol = col
+	v.mode = nvimclient.ModeDisplayName(modeStr)
 
-	row, col, err := v.nvim.GetCursor()
-	if err == nil {
-		v.cursorRow = row
-		v.cursorCol = col
+	if reg, err := v.nvim.IsRecording(); err == nil {
+		v.recording = reg
 	}
+	if v.showsRegister() {
+		if reg, err := v.nvim.GetRegister(`"`); err == nil {
+			v.register = reg
+		}
+	}
+
+	v.selection = nil
+	if v.mode == "VISUAL" || v.mode == "V-LINE" {
+		// On error the selection is simply not drawn; the cursor still is.
+		if sr, sc, er, ec, err := v.nvim.GetVisualSelection(); err == nil {
+			v.selection = &visualSelection{
+				startRow: sr, startCol: runeCol(v.lines, sr, sc),
+				endRow: er, endCol: runeCol(v.lines, er, ec),
+				linewise: v.mode == "V-LINE",
+			}
+		}
+	}
+
+	if v.mode == "V-BLOCK" {
+		if sr, sc, er, ec, err := v.nvim.GetVisualSelection(); err == nil {
+			v.block = &blockSelection{
+				startRow: sr, endRow: er,
+				startCol: min(sc, ec), endCol: max(sc, ec),
+			}
+		}
+	}
+	return true
+}
+
+// showsRegister reports whether the puzzle teaches yank and paste, so the
+// unnamed register is shown under the status bar.
+func (v PuzzleView) showsRegister() bool {
+	return strings.HasPrefix(v.puzzle.Category, "yank")
+}
+
+// recordingText is the status-line indicator for a macro being recorded
+// into reg, like Vim's "recording @a"; it is empty when not recording.
+func recordingText(reg string) string {
+	if reg == "" {
+		return ""
+	}
+	return "recording @" + reg
+}
+
+// registerText formats register contents for a single status line: line
+// breaks are shown as ⏎ and long contents are truncated to width.
+func registerText(content string, width int) string {
+	label := `Register "": `
+	if content == "" {
+		return label + "(empty)"
+	}
+	text := strings.ReplaceAll(content, "\n", "⏎")
+	if avail := width - lipgloss.Width(label); lipgloss.Width(text) > avail {
+		runes := []rune(text)
+		text = string(runes[:max(0, min(len(runes), avail-1))]) + "…"
+	}
+	return label + text
+}
 
-	modeStr, err := v.nvim.GetMode()
-	if err == nil {
-		v.mode = nvimclient.ModeDisplayName(modeStr)
+// noteNvimError shows a warning when err means Neovim stopped responding.
+// Other errors are transient and ignored until the next sync.
+func (v *PuzzleView) noteNvimError(err error) {
+	if errors.Is(err, nvimclient.ErrTimeout) {
+		v.nvimWarning = "Neovim is not responding. Press F5 to reset."
 	}
 }
 
-// syncCheckClear reads buffer text and checks for puzzle completion.
+// nvimRestartMsg asks the app to replace a Neovim that no longer responds.
+type nvimRestartMsg struct{}
+
+// nvimPingTimeout is how long F5 waits for a stuck Neovim before
+// restarting it.
+const nvimPingTimeout = 500 * time.Millisecond
+
+// syncCheckClear checks the buffer read by the last sync for puzzle completion.
 func (v *PuzzleView) syncCheckClear() {
-	if v.state != statePlaying || v.nvim == nil {
+	if v.state != statePlaying {
 		return
 	}
-	text, err := v.nvim.GetBufferText()
-	if err != nil {
+	v.checkClear(strings.Join(v.lines, "\n"))
+}
+
+// checkClear transitions to stateCleared when text satisfies the goal on two
+// consecutive syncs with no input in between. The sandbox never clears.
+// Nothing is cleared while a command is still being typed (e.g. after "d"),
+// since the next key may change the buffer.
+// Nothing is cleared before the first keystroke, so a goal that matches the
+// initial buffer (e.g. an empty goal) can't appear pre-solved.
+func (v *PuzzleView) checkClear(text string) {
+	if v.state != statePlaying || v.keystrokes == 0 || v.puzzle.IsSandbox() {
 		return
 	}
-	if puzzle.Validate(text, v.puzzle.After.Text) {
-		v.state = stateCleared
-		v.stars = puzzle.Score(v.keystrokes, v.puzzle.Par)
-		v.progress.SetBest(v.puzzle.ID, v.stars, v.keystrokes)
+	if v.hasPending() {
+		v.goalSeen = false
+		return
+	}
+	v.cursorMismatch = false
+	if v.stepIndex < len(v.puzzle.Steps) {
+		if puzzle.ValidateMode(text, v.puzzle.Steps[v.stepIndex].Text, v.puzzle.ValidationMode) {
+			v.stepIndex++
+			v.checkpoint = &checkpoint{
+				before:     puzzle.BeforeState{Text: text, Cursor: puzzle.CursorPos{Row: v.cursorRow, Col: v.cursorCol}},
+				keystrokes: v.keystrokes,
+				keyLog:     append([]string(nil), v.keyLog...),
+				stepIndex:  v.stepIndex,
+			}
+		}
+		return
+	}
+	if !v.puzzle.IsSolved(text) {
+		v.goalSeen = false
+		return
+	}
+	if c := v.puzzle.AfterCursor; c != nil && (v.cursorRow != c.Row || v.cursorCol != c.Col) {
+		v.cursorMismatch = true
+		v.goalSeen = false
+		return
+	}
+	if !v.goalSeen {
+		v.goalSeen = true
+		return
+	}
+	v.state = stateCleared
+	v.pauseTimer()
+	v.stars = v.score()
+	v.perfect = v.earnedPerfect()
+	if v.progress == nil {
+		return
+	}
+	v.progress.SetBest(v.puzzle.ID, v.stars, v.keystrokes, v.keyLog)
+	if v.perfect {
+		v.progress.MarkPerfect(v.puzzle.ID)
+	}
+	v.progress.AddTime(v.puzzle.ID, v.elapsed)
+	v.progress.RecordSolve(v.puzzle.ID, progress.Attempt{
+		Keystrokes: v.keystrokes,
+		Par:        v.puzzle.Par,
+		Stars:      v.stars,
+		Time:       v.elapsed,
+		Assisted:   v.assisted(),
+		Revealed:   v.revealedSteps,
+	})
+	v.progress.Save()
+}
+
+// startAttempt loads the puzzle's before state and resets per-attempt state.
+// It is used for both the initial load and resets, and counts as an attempt.
+// Setup keys are sent straight to Neovim and never go through handleNvimInput,
+// so they are not counted toward the score.
+func (v *PuzzleView) startAttempt() {
+	v.keystrokes = 0
+	v.showHint = false
+	v.showSolution = false
+	v.strictDiverged = false
+	v.cursorMismatch = false
+	v.attempt++
+	v.register = ""
+	v.recording = ""
+	v.repeatNote = ""
+	v.goalSeen = false
+	v.perfect = false
+	v.copyStatus = ""
+	v.elapsed = 0
+	v.timerStart = time.Time{}
+	v.timerStarted = false
+	v.clockID = 0
+	v.keyLog = nil
+	v.stepIndex = 0
+	v.checkpoint = nil
+	v.block = nil
+	v.blockInsert = false
+	v.blockApplied = false
+	v.revealedSteps = 0
+	v.solutionViewed = false
+	v.hintUsed = false
+	v.walkthrough = 0
+	v.clearPending()
+	if v.nvim != nil {
+		// Each attempt may start from a different cursor choice.
+		p := v.puzzle
+		p.Before.Seed = rand.IntN(1 << 30)
+		v.nvim.LoadPuzzle(p)
+	}
+	v.syncReadBuffer()
+	if v.progress != nil && !v.puzzle.IsSandbox() {
+		v.progress.RecordAttempt(v.puzzle.ID)
 		v.progress.Save()
 	}
 }
 
+// autoAdvanceCmd schedules the move to the next puzzle after a clear, when
+// AutoAdvance is on and mastery mode isn't asking for a retry.
+func (v PuzzleView) autoAdvanceCmd() tea.Cmd {
+	if !v.settings.AutoAdvance || v.needsMastery() {
+		return nil
+	}
+	attempt := v.attempt
+	return tea.Tick(autoAdvanceDelay, func(time.Time) tea.Msg {
+		return autoAdvanceMsg{attempt: attempt}
+	})
+}
+
+// needsMastery reports whether the mastery setting should hold the user on
+// this puzzle.
+func (v PuzzleView) needsMastery() bool {
+	return v.settings.Mastery && v.stars < puzzle.ThreeStar
+}
+
+// pauseTimer folds the running timer into elapsed and stops it.
+func (v *PuzzleView) pauseTimer() {
+	v.clockID = 0
+	if v.timerStart.IsZero() {
+		return
+	}
+	v.elapsed += time.Since(v.timerStart)
+	v.timerStart = time.Time{}
+}
+
+// startClock (re)starts the timer and its once-per-second display ticker.
+func (v *PuzzleView) startClock() tea.Cmd {
+	v.timerStart = time.Now()
+	clockSeq++
+	v.clockID = clockSeq
+	return v.clockTick()
+}
+
+func (v *PuzzleView) clockTick() tea.Cmd {
+	id := v.clockID
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return clockTickMsg{id: id}
+	})
+}
+
+// currentElapsed returns the solving time including the running segment.
+func (v PuzzleView) currentElapsed() time.Duration {
+	if v.timerStart.IsZero() {
+		return v.elapsed
+	}
+	return v.elapsed + time.Since(v.timerStart)
+}
+
+// handleKey forwards a user key to Neovim, starting the clock on the first one
+// that counts as a keystroke so that reading the goal doesn't cost time. With
+// the solution open the clock waits until it is closed.
+func (v PuzzleView) handleKey(keys string) (PuzzleView, tea.Cmd) {
+	before := v.keystrokes
+	v, cmd := v.handleNvimInput(keys)
+	if v.timerStarted || v.keystrokes <= before {
+		return v, cmd
+	}
+	v.timerStarted = true
+	if v.showSolution {
+		return v, cmd
+	}
+	return v, tea.Batch(cmd, v.startClock())
+}
+
+// restoreCheckpoint reloads the buffer at the last completed step, keeping
+// the keystrokes spent up to that point.
+func (v *PuzzleView) restoreCheckpoint() {
+	cp := v.checkpoint
+	v.keystrokes = cp.keystrokes
+	v.keyLog = append([]string(nil), cp.keyLog...)
+	v.stepIndex = cp.stepIndex
+	v.strictDiverged = false
+	v.cursorMismatch = false
+	v.block = nil
+	v.blockInsert = false
+	v.blockApplied = false
+	v.clearPending()
+	if v.nvim != nil {
+		p := v.puzzle
+		p.Before = cp.before
+		v.nvim.LoadPuzzle(p)
+	}
+	v.syncReadBuffer()
+}
+
+// currentGoal returns the text the user is working toward: the next
+// intermediate step, or the final After state.
+func (v PuzzleView) currentGoal() string {
+	if v.stepIndex < len(v.puzzle.Steps) {
+		return v.puzzle.Steps[v.stepIndex].Text
+	}
+	return v.puzzle.After.Text
+}
+
 func (v PuzzleView) Update(msg tea.Msg) (PuzzleView, tea.Cmd) {
 	switch msg := msg.(type) {
 	case initPuzzleMsg:
-		v.nvim.LoadPuzzle(v.puzzle)
-		v.syncReadBuffer()
+		v.startAttempt()
 		return v, nil
 	case nvimSyncMsg:
-		v.syncReadBuffer()
-		v.syncCheckClear()
+		v.syncQueued = false
+		if v.syncReadBuffer() {
+			v.syncCheckClear()
+		}
+		if v.goalSeen && v.state == statePlaying {
+			// Confirm the goal on the next sync before clearing.
+			return v, v.scheduleSync()
+		}
+		if v.state == stateCleared {
+			return v, v.autoAdvanceCmd()
+		}
 		return v, nil
+	case autoAdvanceMsg:
+		if v.state == stateCleared && msg.attempt == v.attempt {
+			v.clearPending()
+			return v, func() tea.Msg { return puzzleExitMsg{next: true} }
+		}
+		return v, nil
+	case demoStepMsg:
+		if v.state != stateDemo || msg.id != v.demoID {
+			return v, nil
+		}
+		// Show the previous command's result before sending the next one.
+		v.syncReadBuffer()
+		if v.demoStep >= len(v.demoSteps) {
+			return v, nil
+		}
+		// Sent directly rather than through send, so the attempt's key log
+		// stays as the user typed it.
+		if v.nvim != nil {
+			if err := v.nvim.Input(demoKeys(v.demoSteps[v.demoStep].keys)); err != nil {
+				v.noteNvimError(err)
+			}
+		}
+		v.demoStep++
+		return v, v.demoTick()
+	case clockTickMsg:
+		if msg.id != v.clockID || v.timerStart.IsZero() || v.state != statePlaying {
+			return v, nil
+		}
+		return v, v.clockTick()
 
 	case tea.WindowSizeMsg:
 		v.width = msg.Width
@@ -146,52 +623,131 @@ func (v PuzzleView) Update(msg tea.Msg) (PuzzleView, tea.Cmd) {
 		return v, nil
 
 	case tea.KeyMsg:
+		if v.state == stateDemo {
+			switch msg.String() {
+			case "r", "enter":
+				v.state = statePlaying
+				v.startAttempt()
+			case "q", "esc":
+				v.state = stateCleared
+			}
+			return v, nil
+		}
 		if v.state == stateCleared {
 			switch msg.String() {
-			case "enter", "q", "esc":
+			case "enter":
+				if v.needsMastery() {
+					v.state = statePlaying
+					v.startAttempt()
+					return v, nil
+				}
 				v.clearPending()
-				return v, func() tea.Msg { return puzzleExitMsg{next: msg.String() == "enter"} }
-			case "r", "ctrl+r":
-				v.state = statePlaying
-				v.keystrokes = 0
-				v.showHint = false
-				v.showSolution = false
+				return v, func() tea.Msg { return puzzleExitMsg{next: true} }
+			case "n":
+				v.clearPending()
+				return v, func() tea.Msg { return puzzleExitMsg{nextUnsolved: true} }
+			case "s":
+				// Manual advance, even when mastery mode wants a retry.
 				v.clearPending()
-				v.nvim.LoadPuzzle(v.puzzle)
-				v.syncReadBuffer()
+				return v, func() tea.Msg { return puzzleExitMsg{next: true} }
+			case "q", "esc":
+				v.clearPending()
+				return v, func() tea.Msg { return puzzleExitMsg{next: false} }
+			case "r", "f5":
+				v.state = statePlaying
+				v.startAttempt()
+				return v, nil
+			case "d":
+				if v.canCopySolution() {
+					return v, v.startDemo()
+				}
+				return v, nil
+			case "y":
+				if v.canCopySolution() {
+					v.copyStatus = "Solution copied to clipboard."
+					if err := copyToClipboard(v.puzzle.OptimalSolution); err != nil {
+						v.copyStatus = fmt.Sprintf("Copy failed: %v", err)
+					}
+				}
 				return v, nil
 			}
 			return v, nil
 		}
 
+		// In Normal mode with nothing pending, Space walks through the open
+		// solution instead of reaching Neovim, so it isn't counted as a
+		// keystroke. Elsewhere it is typed as usual.
+		if v.showSolution && v.puzzle.OptimalSolution != "" && msg.String() == " " && v.mode == "NORMAL" && !v.hasPending() {
+			v.walkthrough = v.walkthrough%len(solutionSteps(v.puzzle.OptimalSolution)) + 1
+			return v, nil
+		}
+
 		// Playing state controls
 		switch msg.String() {
 		case "ctrl+q":
 			v.clearPending()
+			v.pauseTimer()
 			return v, func() tea.Msg { return puzzleExitMsg{next: false} }
-		case "ctrl+r":
-			v.keystrokes = 0
-			v.showHint = false
-			v.showSolution = false
-			v.clearPending()
-			v.nvim.LoadPuzzle(v.puzzle)
-			v.syncReadBuffer()
+		case "f5":
+			if v.nvimWarning != "" && v.nvim != nil && v.nvim.Ping(nvimPingTimeout) != nil {
+				v.pauseTimer()
+				return v, func() tea.Msg { return nvimRestartMsg{} }
+			}
+			v.nvimWarning = ""
+			// Multi-step puzzles restart from the last completed step.
+			if v.checkpoint != nil {
+				v.restoreCheckpoint()
+				return v, nil
+			}
+			v.startAttempt()
 			return v, nil
 		case "ctrl+h":
 			v.showHint = !v.showHint
+			if v.showHint {
+				v.hintUsed = true
+			}
 			return v, nil
 		case "ctrl+o":
+			if v.solutionsHidden() {
+				return v, nil
+			}
 			v.showSolution = !v.showSolution
+			if v.showSolution {
+				v.solutionViewed = true
+			}
+			// Peeking at the solution doesn't count toward solving time.
+			if v.showSolution {
+				v.pauseTimer()
+			} else if v.timerStarted {
+				return v, v.startClock()
+			}
+			return v, nil
+		case "f2":
+			v.showCounts = !v.showCounts
+			return v, nil
+		case "f4":
+			v.showKeyLog = !v.showKeyLog
+			return v, nil
+		case "f6":
+			v.ghost = !v.ghost
+			return v, nil
+		case "f3":
+			if v.solutionsHidden() {
+				return v, nil
+			}
+			if v.revealedSteps < puzzle.KeyCount(v.puzzle.OptimalSolution) {
+				v.revealedSteps++
+			}
 			return v, nil
 		default:
 			keys := translateKey(msg)
 			debugKeyInput(msg, keys)
-			return v.handleNvimInput(keys)
+			return v.handleKey(keys)
 		}
 	default:
 		if keys := translateCSIu(msg); keys != "" {
 			debugKeyInput(msg, keys)
-			return v.handleNvimInput(keys)
+			return v.handleKey(keys)
 		}
 	}
 
@@ -225,27 +781,46 @@ func (v PuzzleView) View() string {
 		maxEditorLines = min(maxEditorLines, height)
 	}
 
-	maxGoalLines := countLines(v.puzzle.After.Text)
+	maxGoalLines := countLines(v.currentGoal())
 	if height <= 0 {
 		return v.renderView(contentWidth, innerWidth, maxGoalLines, maxEditorLines)
 	}
 
-	for editorLines := maxEditorLines; editorLines >= 1; editorLines-- {
-		for goalLines := maxGoalLines; goalLines >= 1; goalLines-- {
-			view := v.renderView(contentWidth, innerWidth, goalLines, editorLines)
-			if lipgloss.Height(view) <= height {
-				return view
-			}
+	// Measure everything else with one line of each box, then give the spare
+	// rows to the editor first and the goal second.
+	minimal := v.renderView(contentWidth, innerWidth, 1, 1)
+	spare := height - lipgloss.Height(minimal)
+	if spare <= 0 {
+		return minimal
+	}
+	editorLines := min(maxEditorLines, 1+spare)
+	goalLines := min(maxGoalLines, 1+spare-(editorLines-1))
+	if editorLines == 1 && goalLines == 1 {
+		return minimal
+	}
+	// Editor lines are truncated to one row each, but long goal lines wrap,
+	// so the goal may still need trimming.
+	for {
+		view := v.renderView(contentWidth, innerWidth, goalLines, editorLines)
+		if goalLines == 1 || lipgloss.Height(view) <= height {
+			return view
 		}
+		goalLines--
 	}
-
-	return v.renderView(contentWidth, innerWidth, 1, 1)
 }
 
 func (v PuzzleView) renderView(contentWidth, innerWidth, goalLines, editorLines int) string {
+	sandbox := v.puzzle.IsSandbox()
 	header := fmt.Sprintf("Level %d: %s", v.puzzle.Level, v.puzzle.Title)
 	info := fmt.Sprintf("Category: %s", v.puzzle.Category)
-	if progressText := overallProgressText(v.progress, v.allPuzzles); progressText != "" {
+	if sandbox {
+		header = v.puzzle.Title
+		info = "Free practice: nothing is scored or saved."
+	}
+	if steps := len(v.puzzle.Steps); steps > 0 {
+		info += fmt.Sprintf("  Step %d/%d", min(v.stepIndex+1, steps+1), steps+1)
+	}
+	if progressText := overallProgressText(v.progress, v.allPuzzles); progressText != "" && !sandbox {
 		info += "  " + progressText
 	}
 	headerBlock := titleStyle.MaxWidth(contentWidth).Render(header)
@@ -261,57 +836,592 @@ func (v PuzzleView) renderView(contentWidth, innerWidth, goalLines, editorLines
 
 	modeDisplay := ModeStyle(v.mode).Render(fmt.Sprintf(" %s ", v.mode))
 	keystrokeDisplay := fmt.Sprintf("Keystrokes: %d", v.keystrokes)
-	parDisplay := mutedStyle.Render(fmt.Sprintf("(par: %d)", v.puzzle.Par))
-	statusLine := fmt.Sprintf("%s  %s %s", modeDisplay, keystrokeDisplay, parDisplay)
+	if v.settings.ShowTimer {
+		keystrokeDisplay += "  Time: " + formatClock(v.currentElapsed())
+	}
+	statusLine := fmt.Sprintf("%s  %s", modeDisplay, keystrokeDisplay)
+	if !sandbox {
+		statusLine += " " + mutedStyle.Render(fmt.Sprintf("(%s)", v.parText()))
+	}
+	if rec := recordingText(v.recording); rec != "" {
+		statusLine += "  " + recordingStyle.Render(rec)
+	}
+	if v.repeatNote != "" {
+		statusLine += "  " + mutedStyle.Render(v.repeatNote)
+	}
+	if v.showCounts {
+		statusLine += "  " + mutedStyle.Render(v.countsText())
+	}
+	if showcmd := v.showcmd(); showcmd != "" {
+		// Right-align the pending command like Vim's showcmd.
+		pad := contentWidth - lipgloss.Width(statusLine) - lipgloss.Width(showcmd)
+		statusLine += strings.Repeat(" ", max(2, pad)) + showcmd
+	}
 	statusBlock := statusBarStyle.MaxWidth(contentWidth).Render(statusLine)
 
-	parts := []string{
-		headerBlock,
-		infoBlock,
-		"",
-		goalLabel,
-		goalBox,
-		"",
-		editorLabel,
-		editorBox,
-		"",
-		statusBlock,
+	parts := []string{headerBlock, infoBlock, ""}
+	if !sandbox {
+		parts = append(parts, goalLabel, goalBox, "")
+	}
+	parts = append(parts, editorLabel, editorBox)
+	if v.showKeyLog {
+		parts = append(parts, mutedStyle.Render(keyLogLine(v.keyLog, contentWidth)))
+	}
+	parts = append(parts, "", statusBlock)
+	if v.showsRegister() {
+		parts = append(parts, mutedStyle.Render(registerText(v.register, contentWidth)))
 	}
 
+	if v.nvimWarning != "" {
+		parts = append(parts, dangerStyle.MaxWidth(contentWidth).Render(v.nvimWarning))
+	}
+	if v.cursorMismatch && v.state == statePlaying {
+		parts = append(parts, mutedStyle.MaxWidth(contentWidth).Render("Text correct — check your cursor position."))
+	}
+	if v.strictDiverged && v.state == statePlaying {
+		parts = append(parts, dangerStyle.MaxWidth(contentWidth).Render("Mistake! Fix it (backspace/undo) to continue."))
+	}
+	if hint := v.efficiencyHint(); hint != "" {
+		parts = append(parts, hintStyle.Width(contentWidth).Render("Efficiency: "+hint))
+	}
 	if v.showHint {
 		parts = append(parts, hintStyle.Width(contentWidth).Render("Hint: "+v.puzzle.Hint))
 	}
+	if v.state == statePlaying && v.revealedSteps > 0 && !v.showSolution {
+		parts = append(parts, solutionStyle.Width(contentWidth).Render("Next keys: "+v.revealedKeys()))
+	}
 	if v.state == statePlaying && v.showSolution && v.puzzle.OptimalSolution != "" {
 		parts = append(parts, solutionStyle.Width(contentWidth).Render("Solution: "+v.puzzle.OptimalSolution))
 		if v.puzzle.SolutionExplanation != "" {
 			parts = append(parts, explanationStyle.Width(contentWidth).Render(v.puzzle.SolutionExplanation))
 		}
+		parts = append(parts, solutionStyle.Width(contentWidth).Render(v.solutionStepText()))
 	}
 
 	if v.state == stateCleared {
 		starDisplay := FormatStars(int(v.stars))
+		if v.perfect {
+			starDisplay += PerfectBadge(true)
+		}
+		if v.perfect {
+			starDisplay += " " + perfectStyle.Render("PERFECT — matched the optimal solution!")
+		}
+		actions := "[enter] next  [n] next unsolved  [r] retry  [q] back"
+		if v.needsMastery() {
+			actions = "Three stars needed to advance!\n[enter] retry  [s] skip  [n] next unsolved  [q] back"
+		}
+		if v.canCopySolution() {
+			actions += "  [y] copy solution  [d] demo"
+		}
+		if v.copyStatus != "" {
+			actions += "\n" + v.copyStatus
+		}
+		assistNote := ""
+		if v.assisted() {
+			assistNote = "\n" + v.assistText()
+		}
+		if v.hintUsed && !v.settings.FreeHints {
+			assistNote += fmt.Sprintf("\nHint used (+%d keys when scoring)", v.puzzle.HintPenalty())
+		}
+		tips := ""
+		for _, tip := range puzzle.EfficiencyTips(v.keyLog, v.puzzle.OptimalSolution) {
+			tips += "\nTip: " + tip
+		}
 		clearMsg := fmt.Sprintf(
-			"Cleared! %s\n\nKeystrokes: %d  (par: %d)\nOptimal: %s\n\n[enter] next  [r] retry  [q] back",
-			starDisplay, v.keystrokes, v.puzzle.Par, v.puzzle.OptimalSolution,
+			"Cleared! %s\n\nKeystrokes: %d  (%s)\nTime: %s%s\nOptimal: %s%s\n\n%s",
+			starDisplay, v.keystrokes, v.parText(), formatElapsed(v.elapsed), assistNote, v.puzzle.OptimalSolution, tips, actions,
 		)
 		parts = append(parts, "", successStyle.MaxWidth(contentWidth).Render(clearMsg))
+		if diff := renderTextDiff(v.puzzle.Before.Text, v.currentGoal(), clearDiffLines); diff != "" {
+			parts = append(parts, labelStyle.Render(" CHANGES "), lipgloss.NewStyle().MaxWidth(contentWidth).Render(diff))
+		}
+	} else if v.state == stateDemo {
+		parts = append(parts, "", solutionStyle.Width(contentWidth).Render(v.demoText()))
+		parts = append(parts, helpStyle.MaxWidth(contentWidth).Render("[r] try it yourself  [q] back"))
 	} else {
-		helpLine := "Ctrl+H: hint  Ctrl+O: solution  Ctrl+R: reset  Ctrl+Q: quit"
+		helpLine := "Ctrl+H: hint  Ctrl+O: solution  F2: counts  F3: next key  F4: keys  F5: reset  F6: diff  Ctrl+Q: quit"
 		parts = append(parts, helpStyle.MaxWidth(contentWidth).Render(helpLine))
 	}
 
 	return strings.Join(parts, "\n")
 }
 
+// efficiencyHint returns the puzzle's efficiency hint once the user has
+// repeatedly solved it over par.
+func (v PuzzleView) efficiencyHint() string {
+	if v.puzzle.EfficiencyHint == "" || v.progress == nil {
+		return ""
+	}
+	if v.progress.OverParSolves(v.puzzle.ID) < v.puzzle.EfficiencyHintThreshold() {
+		return ""
+	}
+	return v.puzzle.EfficiencyHint
+}
+
+// parText formats par, plus the community average when the puzzle has one.
+func (v PuzzleView) parText() string {
+	text := fmt.Sprintf("par: %d", v.puzzle.Par)
+	if v.puzzle.EffectiveScoreMode() == puzzle.ScoreModeGolf {
+		text = fmt.Sprintf("golf, optimal: %d", v.puzzle.OptimalLength())
+	}
+	if v.puzzle.CommunityPar > 0 {
+		text += fmt.Sprintf(", community: %d", v.puzzle.CommunityPar)
+	}
+	return text
+}
+
+// canCopySolution reports whether the optimal solution may be copied.
+func (v PuzzleView) canCopySolution() bool {
+	return !v.solutionsHidden() && v.puzzle.OptimalSolution != ""
+}
+
+// solutionsHidden reports whether solution reveal and copy are disabled,
+// by the HideSolutions setting or VIMGYM_NO_SOLUTIONS.
+func (v PuzzleView) solutionsHidden() bool {
+	return solutionsDisabled || v.settings.HideSolutions
+}
+
+// showcmd returns the buffered count and pending keys, e.g. "3d".
+func (v PuzzleView) showcmd() string {
+	return strings.ReplaceAll(v.pendingCount+v.pendingKeys, "<LT>", "<")
+}
+
+// revealedKeys shows the revealed prefix of the optimal solution.
+func (v PuzzleView) revealedKeys() string {
+	keys := puzzle.SplitKeys(v.puzzle.OptimalSolution)
+	n := min(v.revealedSteps, len(keys))
+	shown := strings.Join(keys[:n], " ")
+	if n < len(keys) {
+		shown += " …"
+	}
+	return shown
+}
+
+// solutionStep is one command of a solution, with a short explanation.
+type solutionStep struct {
+	keys string
+	desc string
+}
+
+// solutionOperators name the operators that take a motion or text object.
+var solutionOperators = map[string]string{
+	"d": "delete", "c": "change", "y": "yank", ">": "indent", "<": "dedent", "=": "reindent",
+	"gu": "lowercase", "gU": "uppercase", "g~": "toggle case of", "gq": "format",
+}
+
+// solutionMotions describe motions, whether used alone or after an operator.
+var solutionMotions = map[string]string{
+	"h": "left", "l": "right", "j": "down", "k": "up",
+	"w": "to the next word", "W": "to the next WORD", "b": "back a word", "B": "back a WORD",
+	"e": "to the end of the word", "E": "to the end of the WORD",
+	"0": "to the start of the line", "^": "to the first non-blank", "$": "to the end of the line",
+	"gg": "to the first line", "G": "to the last line", "H": "to the top of the screen",
+	"M": "to the middle of the screen", "L": "to the bottom of the screen",
+	"{": "to the previous blank line", "}": "to the next blank line", "%": "to the matching bracket",
+	"n": "to the next match", "N": "to the previous match",
+	"*": "to the next match of the word under the cursor", "#": "to the previous match of the word under the cursor",
+}
+
+// solutionCharMotions describe motions that take a character.
+var solutionCharMotions = map[string]string{
+	"f": "to the next %q", "t": "till the next %q", "F": "back to the previous %q", "T": "back till the previous %q",
+}
+
+// solutionTextObjects name the objects after i (inside) or a (around).
+var solutionTextObjects = map[string]string{
+	"w": "word", "W": "WORD", "s": "sentence", "p": "paragraph", "t": "tag",
+	`"`: "quotes", "'": "single quotes", "`": "backticks",
+	"(": "parentheses", ")": "parentheses", "b": "parentheses",
+	"[": "brackets", "]": "brackets", "{": "braces", "}": "braces", "B": "braces",
+	"<": "angle brackets", ">": "angle brackets",
+}
+
+// solutionInserts describe the keys that enter insert mode.
+var solutionInserts = map[string]string{
+	"i": "insert before the cursor", "a": "append after the cursor",
+	"I": "insert at the start of the line", "A": "append at the end of the line",
+	"o": "open a line below", "O": "open a line above",
+	"s": "substitute the character", "S": "substitute the line", "C": "change to the end of the line",
+}
+
+// solutionCommands describe single commands that take no motion.
+var solutionCommands = map[string]string{
+	"x": "delete the character under the cursor", "X": "delete the character before the cursor",
+	"D": "delete to the end of the line", "Y": "yank the line",
+	"p": "put after the cursor", "P": "put before the cursor",
+	"u": "undo", "<C-r>": "redo", ".": "repeat the last change",
+	"J": "join the line below", "gJ": "join the line below without a space", "~": "toggle case",
+	"<C-a>": "increment the number", "<C-x>": "decrement the number",
+	"v": "start visual mode", "V": "start visual line mode", "<C-v>": "start visual block mode",
+	"gv": "reselect the last visual area", "<Esc>": "back to normal mode",
+}
+
+// solutionVisualOps describe commands that act on a visual selection.
+var solutionVisualOps = map[string]string{
+	"d": "delete the selection", "x": "delete the selection", "y": "yank the selection",
+	"c": "change the selection", "s": "change the selection",
+	"I": "insert before the block", "A": "append after the block",
+	">": "indent the selection", "<": "dedent the selection", "=": "reindent the selection",
+	"~": "toggle case of the selection", "u": "lowercase the selection", "U": "uppercase the selection",
+	"J": "join the selected lines", "p": "put over the selection", "P": "put over the selection",
+}
+
+// solutionSteps splits a solution into commands, e.g. `3dwci"x<Esc>` into
+// "3dw" and `ci"x<Esc>`, each explained, so it can be walked through one
+// command at a time. Unrecognized commands get an empty description.
+func solutionSteps(seq string) []solutionStep {
+	keys := puzzle.SplitKeys(seq)
+	var steps []solutionStep
+	visual, recording := false, false
+	for i := 0; i < len(keys); {
+		start := i
+		count, register := "", ""
+		for i < len(keys) {
+			if isDigitKey(keys[i]) && (keys[i] != "0" || count != "") {
+				count += keys[i]
+				i++
+			} else if keys[i] == `"` && i+1 < len(keys) {
+				register = keys[i+1]
+				i += 2
+			} else {
+				break
+			}
+		}
+		if i >= len(keys) {
+			steps = append(steps, solutionStep{keys: strings.Join(keys[start:], "")})
+			break
+		}
+
+		var end int
+		var desc string
+		end, desc, visual, recording = nextSolutionCommand(keys, i, visual, recording)
+		if desc != "" && count != "" {
+			desc += " ×" + count
+		}
+		if desc != "" && register != "" {
+			desc += fmt.Sprintf(" (register %s)", register)
+		}
+		steps = append(steps, solutionStep{keys: strings.Join(keys[start:end], ""), desc: desc})
+		i = end
+	}
+	return steps
+}
+
+// nextSolutionCommand reads the command starting at keys[i], past any count
+// or register, returning where it ends, its description, and whether visual
+// mode and macro recording are active afterwards.
+func nextSolutionCommand(keys []string, i int, visual, recording bool) (int, string, bool, bool) {
+	n := len(keys)
+	k := keys[i]
+	arg := func(j int) string {
+		if j < n {
+			return keys[j]
+		}
+		return ""
+	}
+
+	switch {
+	case k == ":" || k == "/" || k == "?":
+		end := untilKey(keys, i+1, "<CR>", "<Enter>", "<Esc>")
+		body := strings.Join(keys[i+1:end], "")
+		body = strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(body, "<CR>"), "<Enter>"), "<Esc>")
+		switch k {
+		case ":":
+			return end, "run the Ex command :" + body, false, recording
+		case "/":
+			return end, fmt.Sprintf("search forward for %q", body), false, recording
+		default:
+			return end, fmt.Sprintf("search backward for %q", body), false, recording
+		}
+	case k == "q" && recording:
+		return i + 1, "stop recording the macro", visual, false
+	case k == "q":
+		return min(i+2, n), "record a macro into register " + arg(i+1), visual, true
+	case k == "@":
+		return min(i+2, n), "run the macro in register " + arg(i+1), visual, recording
+	case k == "m":
+		return min(i+2, n), "set mark " + arg(i+1), visual, recording
+	case k == "'" || k == "`":
+		return min(i+2, n), "jump to mark " + arg(i+1), visual, recording
+	case k == "r" && visual:
+		return min(i+2, n), fmt.Sprintf("replace every selected character with %q", arg(i+1)), false, recording
+	case k == "r":
+		return min(i+2, n), fmt.Sprintf("replace the character with %q", arg(i+1)), visual, recording
+	}
+
+	if visual {
+		if desc, ok := solutionVisualOps[k]; ok {
+			end := i + 1
+			if k == "c" || k == "s" || k == "I" || k == "A" {
+				end = untilKey(keys, end, "<Esc>")
+				desc += typedText(keys[i+1 : end])
+			}
+			return end, desc, false, recording
+		}
+		if k == "<Esc>" || k == "v" || k == "V" || k == "<C-v>" {
+			return i + 1, "leave visual mode", false, recording
+		}
+		end, desc := solutionMotion(keys, i)
+		return end, desc, true, recording
+	}
+
+	if desc, ok := solutionInserts[k]; ok {
+		end := untilKey(keys, i+1, "<Esc>")
+		return end, desc + typedText(keys[i+1:end]), false, recording
+	}
+	if op := operatorAt(keys, i); op != "" {
+		j := i + len(puzzle.SplitKeys(op))
+		for j < n && isDigitKey(keys[j]) && keys[j] != "0" {
+			j++
+		}
+		var target string
+		switch {
+		case arg(j) == op[len(op)-1:]:
+			j, target = j+1, "the line"
+		case arg(j) == "i" || arg(j) == "a":
+			where := "inside"
+			if arg(j) == "a" {
+				where = "around"
+			}
+			target = where + " " + solutionTextObjects[arg(j+1)]
+			j = min(j+2, n)
+		default:
+			j, target = solutionMotion(keys, j)
+		}
+		desc := solutionOperators[op] + " " + target
+		if op == "c" {
+			end := untilKey(keys, j, "<Esc>")
+			desc += typedText(keys[j:end])
+			j = end
+		}
+		return j, desc, false, recording
+	}
+	if end := i + 2; end <= n && solutionCommands[k+keys[i+1]] != "" {
+		return end, solutionCommands[k+keys[i+1]], false, recording
+	}
+	if desc, ok := solutionCommands[k]; ok {
+		return i + 1, desc, k == "v" || k == "V" || k == "<C-v>", recording
+	}
+	end, desc := solutionMotion(keys, i)
+	if desc != "" {
+		desc = "move " + desc
+	}
+	return end, desc, false, recording
+}
+
+// solutionMotion reads the motion at keys[i] and describes where it goes.
+func solutionMotion(keys []string, i int) (int, string) {
+	if i >= len(keys) {
+		return i, ""
+	}
+	k := keys[i]
+	if format, ok := solutionCharMotions[k]; ok {
+		if i+1 < len(keys) {
+			return i + 2, fmt.Sprintf(format, keys[i+1])
+		}
+		return i + 1, ""
+	}
+	if (k == "g" || k == "z" || k == "[" || k == "]") && i+1 < len(keys) {
+		return i + 2, solutionMotions[k+keys[i+1]]
+	}
+	return i + 1, solutionMotions[k]
+}
+
+// operatorAt returns the operator starting at keys[i] (e.g. "d" or "gU"),
+// or "" if there is none.
+func operatorAt(keys []string, i int) string {
+	if i+1 < len(keys) && keys[i] == "g" {
+		if op := "g" + keys[i+1]; solutionOperators[op] != "" {
+			return op
+		}
+	}
+	if solutionOperators[keys[i]] != "" {
+		return keys[i]
+	}
+	return ""
+}
+
+// untilKey returns the index just past the first of stops at or after i, or
+// len(keys) if none follows.
+func untilKey(keys []string, i int, stops ...string) int {
+	for ; i < len(keys); i++ {
+		for _, s := range stops {
+			if keys[i] == s {
+				return i + 1
+			}
+		}
+	}
+	return len(keys)
+}
+
+// typedText describes the text typed in insert mode, dropping the <Esc>
+// that ends it.
+func typedText(keys []string) string {
+	if len(keys) > 0 && keys[len(keys)-1] == "<Esc>" {
+		keys = keys[:len(keys)-1]
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", typing %q", strings.Join(keys, ""))
+}
+
+// startDemo reloads the puzzle and starts playing the optimal solution
+// into Neovim. The demo leaves the scored attempt, progress and attempt
+// count untouched.
+func (v *PuzzleView) startDemo() tea.Cmd {
+	v.state = stateDemo
+	v.demoID++
+	v.demoSteps = solutionSteps(v.puzzle.OptimalSolution)
+	v.demoStep = 0
+	v.clearPending()
+	if v.nvim != nil {
+		// The optimal solution is written for the default start cursor.
+		p := v.puzzle
+		p.Before.CursorChoices = nil
+		v.nvim.LoadPuzzle(p)
+	}
+	v.syncReadBuffer()
+	return v.demoTick()
+}
+
+// demoTick schedules the next command of the running demo.
+func (v PuzzleView) demoTick() tea.Cmd {
+	id := v.demoID
+	return tea.Tick(demoStepDelay, func(time.Time) tea.Msg {
+		return demoStepMsg{id: id}
+	})
+}
+
+// demoText describes the demo's progress and the command just played.
+func (v PuzzleView) demoText() string {
+	text := "DEMO (not scored): playing the optimal solution"
+	if v.demoStep > 0 {
+		step := v.demoSteps[v.demoStep-1]
+		text = fmt.Sprintf("DEMO (not scored) %d/%d: %s", v.demoStep, len(v.demoSteps), step.keys)
+		if step.desc != "" {
+			text += " — " + step.desc
+		}
+	}
+	if v.demoStep == len(v.demoSteps) {
+		text += "\nDemo finished."
+	}
+	return text
+}
+
+// demoKeys converts solution keys to Neovim input, escaping a literal "<"
+// (e.g. typed HTML) so it isn't read as the start of a key name.
+func demoKeys(keys string) string {
+	split := puzzle.SplitKeys(keys)
+	for i, k := range split {
+		if k == "<" {
+			split[i] = "<LT>"
+		}
+	}
+	return strings.Join(split, "")
+}
+
+// solutionStepText renders the current step of the solution walkthrough.
+func (v PuzzleView) solutionStepText() string {
+	steps := solutionSteps(v.puzzle.OptimalSolution)
+	if v.walkthrough == 0 || len(steps) == 0 {
+		return "space: step through one command at a time"
+	}
+	step := steps[min(v.walkthrough, len(steps))-1]
+	text := fmt.Sprintf("Step %d/%d: %s", v.walkthrough, len(steps), step.keys)
+	if step.desc != "" {
+		text += " — " + step.desc
+	}
+	return text
+}
+
+// earnedPerfect reports whether the clear earns the perfect badge: three
+// stars, at or under the optimal solution's length, with neither the hint
+// nor any of the solution seen, whatever the free hints setting.
+func (v PuzzleView) earnedPerfect() bool {
+	return v.stars == puzzle.ThreeStar && !v.hintUsed && !v.assisted() && v.puzzle.IsPerfect(v.keystrokes)
+}
+
+// assisted reports whether the solution (or part of it) was revealed this attempt.
+func (v PuzzleView) assisted() bool {
+	return v.solutionViewed || v.revealedSteps > 0
+}
+
+func (v PuzzleView) assistText() string {
+	text := fmt.Sprintf("Assisted (%d/%d keys revealed)", v.revealedSteps, puzzle.KeyCount(v.puzzle.OptimalSolution))
+	if v.solutionViewed {
+		text = "Assisted (solution viewed)"
+	}
+	if !v.settings.FreeHints {
+		text += ", at most two stars"
+	}
+	return text
+}
+
+// score rates the clear, charging for the hint and solution unless the
+// free hints setting is on.
+func (v PuzzleView) score() puzzle.StarRating {
+	if v.settings.FreeHints {
+		return v.puzzle.Score(v.keystrokes)
+	}
+	return v.puzzle.ScoreAssisted(v.keystrokes, v.hintUsed, v.assisted())
+}
+
+// keyLogLine renders the key log on one line, keeping the most recent
+// commands that fit within width.
+func keyLogLine(log []string, width int) string {
+	const prefix = "Keys: "
+	if len(log) == 0 {
+		return prefix + "(none)"
+	}
+	line := ""
+	for i := len(log) - 1; i >= 0; i-- {
+		key := strings.ReplaceAll(log[i], "<LT>", "<")
+		next := key
+		if line != "" {
+			next = key + " " + line
+		}
+		if lipgloss.Width(prefix+"… "+next) > width {
+			return prefix + "… " + line
+		}
+		line = next
+	}
+	return prefix + line
+}
+
+// formatElapsed formats a duration as seconds with one decimal (e.g. 12.3s).
+func formatElapsed(d time.Duration) string {
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// formatClock formats a duration as a stopwatch (e.g. 1:05).
+func formatClock(d time.Duration) string {
+	secs := int(d / time.Second)
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
+
 func (v PuzzleView) renderGoalContent(height int) string {
 	if height < 1 {
 		height = 1
 	}
-	afterLines := strings.Split(v.puzzle.After.Text, "\n")
+	goal := v.currentGoal()
+	if goal == "" {
+		return mutedStyle.Render("(empty buffer)")
+	}
+	afterLines := strings.Split(goal, "\n")
 	beforeLines := strings.Split(v.puzzle.Before.Text, "\n")
 	focusRow := goalFocusRow(beforeLines, afterLines)
 	start, end := windowRange(len(afterLines), focusRow, height)
-	return strings.Join(afterLines[start:end], "\n")
+	// Tabs are expanded like the editor's, not by the box's own tab width.
+	shown := make([]string, 0, end-start)
+	for _, l := range afterLines[start:end] {
+		l = expandTabs(l)
+		if v.puzzle.Language != "" {
+			l = highlightLine(l, v.puzzle.Language)
+		}
+		shown = append(shown, l)
+	}
+	return strings.Join(shown, "\n")
 }
 
 func goalFocusRow(before, after []string) int {
@@ -344,6 +1454,20 @@ func goalFocusRow(before, after []string) int {
 	return len(after) - 1
 }
 
+// countsText formats the word/char counts of the buffer against the goal.
+func (v PuzzleView) countsText() string {
+	words, chars := textCounts(strings.Join(v.lines, "\n"))
+	goalWords, goalChars := textCounts(v.currentGoal())
+	return fmt.Sprintf("Words: %d/%d  Chars: %d/%d", words, goalWords, chars, goalChars)
+}
+
+// textCounts returns the number of words and characters in text.
+// Newlines are not counted as characters.
+func textCounts(text string) (int, int) {
+	text = strings.TrimRight(text, "\n")
+	return len(strings.Fields(text)), utf8.RuneCountInString(strings.ReplaceAll(text, "\n", ""))
+}
+
 func countLines(text string) int {
 	if text == "" {
 		return 1
@@ -363,13 +1487,24 @@ func (v PuzzleView) renderBuffer(width, height int) string {
 		height = 1
 	}
 
+	var goalLines []string
+	if v.ghost {
+		goalLines = strings.Split(v.currentGoal(), "\n")
+	}
 	start, end := windowRange(len(v.lines), v.cursorRow, height)
 	rendered := make([]string, 0, end-start)
 	for i := start; i < end; i++ {
 		line := v.lines[i]
-		if i == v.cursorRow {
-			rendered = append(rendered, v.renderLineWithCursor(line, v.cursorCol, width))
-		} else {
+		hl := v.lineHighlight(i)
+		kinds := v.lineKinds(line, i, goalLines)
+		switch {
+		case i == v.cursorRow:
+			// Neovim's cursor column is a byte offset.
+			col := runeCol(v.lines, i, v.cursorCol)
+			rendered = append(rendered, v.renderLineWithCursor(line, col, width, hl, kinds))
+		case hl != nil || kinds != nil:
+			rendered = append(rendered, v.renderLineWithCursor(line, -1, width, hl, kinds))
+		default:
 			rendered = append(rendered, truncateLine(line, width))
 		}
 	}
@@ -377,76 +1512,273 @@ func (v PuzzleView) renderBuffer(width, height int) string {
 	return strings.Join(rendered, "\n")
 }
 
-// renderLineWithCursor renders a line with the cursor position highlighted.
-func (v PuzzleView) renderLineWithCursor(line string, col int, width int) string {
-	if width < 1 {
-		width = 1
+// lineKinds returns how each rune of buffer row row is colored: by the ghost
+// diff against goalLines when it differs from the goal, otherwise by syntax.
+// It is nil for plain text.
+func (v PuzzleView) lineKinds(line string, row int, goalLines []string) []tokenKind {
+	if v.ghost {
+		goal, ok := "", row < len(goalLines)
+		if ok {
+			goal = goalLines[row]
+		}
+		if !ok || goal != line {
+			return ghostKinds(line, goal)
+		}
 	}
+	return syntaxKinds(line, v.puzzle.Language)
+}
+
+// ghostKinds marks line as differing from goal: the runes between their
+// common prefix and suffix are tokenGhostDiff, the rest tokenGhost.
+func ghostKinds(line, goal string) []tokenKind {
 	runes := []rune(line)
-	if col < 0 {
-		col = 0
+	prefix, suffix := commonAffixes(runes, []rune(goal))
+	kinds := make([]tokenKind, len(runes))
+	for i := range kinds {
+		kinds[i] = tokenGhost
+		if i >= prefix && i < len(runes)-suffix {
+			kinds[i] = tokenGhostDiff
+		}
 	}
-	if col > len(runes) {
-		col = len(runes)
+	return kinds
+}
+
+// lineHighlight returns which columns of a buffer row are highlighted
+// (by a visual or visual-block selection), or nil when the row has none.
+func (v PuzzleView) lineHighlight(row int) func(col int) bool {
+	if sel := v.selection; sel != nil && (v.mode == "VISUAL" || v.mode == "V-LINE") {
+		if row < sel.startRow || row > sel.endRow {
+			return nil
+		}
+		if sel.linewise {
+			return func(int) bool { return true }
+		}
+		return func(col int) bool {
+			return (row > sel.startRow || col >= sel.startCol) && (row < sel.endRow || col <= sel.endCol)
+		}
+	}
+	b := v.block
+	if b == nil || row < b.startRow || row > b.endRow {
+		return nil
 	}
+	switch {
+	case v.mode == "V-BLOCK":
+		return func(col int) bool { return col >= b.startCol && col <= b.endCol }
+	case v.blockInsert:
+		// Mark where the block insert will land on every row of the block.
+		insertCol := b.startCol
+		if v.blockAppend {
+			insertCol = b.endCol + 1
+		}
+		return func(col int) bool { return col == insertCol }
+	case v.blockApplied:
+		return func(int) bool { return true }
+	}
+	return nil
+}
 
-	if len(runes) <= width {
-		cursorIdx := -1
-		if col < len(runes) {
-			cursorIdx = col
+// glyph is one displayed cell group of a buffer line: a rune together with
+// any zero-width runes (combining marks) that follow it.
+type glyph struct {
+	text  string
+	col   int // rune index of the glyph's first rune
+	width int // display cells
+}
+
+// tabStop is the tab width buffers are shown with: Neovim's default, which
+// the puzzles' --clean instance keeps.
+const tabStop = 8
+
+// lineGlyphs splits line into glyphs, measuring each in display cells so
+// full-width characters take two. Tabs become spaces up to the next tab stop.
+func lineGlyphs(line string) []glyph {
+	var glyphs []glyph
+	col, pos := 0, 0
+	for _, r := range line {
+		switch w := lipgloss.Width(string(r)); {
+		case r == '\t':
+			w = tabStop - pos%tabStop
+			glyphs = append(glyphs, glyph{text: strings.Repeat(" ", w), col: col, width: w})
+			pos += w
+		case w == 0 && len(glyphs) > 0:
+			glyphs[len(glyphs)-1].text += string(r)
+		default:
+			glyphs = append(glyphs, glyph{text: string(r), col: col, width: w})
+			pos += w
 		}
-		return renderCursorInRunes(runes, cursorIdx, col == len(runes), width)
+		col++
+	}
+	return glyphs
+}
+
+// expandTabs replaces the tabs in line with spaces up to each tab stop.
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var b strings.Builder
+	for _, g := range lineGlyphs(line) {
+		b.WriteString(g.text)
 	}
+	return b.String()
+}
 
-	start := col - width/2
-	if start < 0 {
-		start = 0
+// glyphsWidth returns the display width of glyphs.
+func glyphsWidth(glyphs []glyph) int {
+	w := 0
+	for _, g := range glyphs {
+		w += g.width
+	}
+	return w
+}
+
+// glyphAt returns the index of the glyph holding rune col, or len(glyphs)
+// when col is past the end of the line.
+func glyphAt(glyphs []glyph, col int) int {
+	for i := len(glyphs) - 1; i >= 0; i-- {
+		if g := glyphs[i]; g.col <= col {
+			if i == len(glyphs)-1 && col >= g.col+utf8.RuneCountInString(g.text) {
+				break
+			}
+			return i
+		}
 	}
-	if start+width > len(runes) {
-		start = len(runes) - width
+	return len(glyphs)
+}
+
+// renderLineWithCursor renders a line with the cursor position highlighted.
+// A negative col renders the line without a cursor. hl, if non-nil, marks
+// highlighted columns. The colors in kinds (see lineKinds) apply under both.
+// col, hl and kinds index runes; the line is fitted to width display cells,
+// with a "~" on each side where it is clipped.
+func (v PuzzleView) renderLineWithCursor(line string, col int, width int, hl func(int) bool, kinds []tokenKind) string {
+	if width < 1 {
+		width = 1
 	}
-	end := start + width
-	visible := runes[start:end]
+	glyphs := lineGlyphs(line)
+	runeCount := utf8.RuneCountInString(line)
 	cursorIdx := -1
-	if col >= start && col < end {
-		cursorIdx = col - start
+	pastEnd := col >= runeCount
+	if col >= 0 {
+		col = min(col, runeCount)
+		if pastEnd {
+			// A cursor past the end sits on a space of its own.
+			glyphs = append(glyphs, glyph{text: " ", col: runeCount, width: 1})
+		}
+		cursorIdx = glyphAt(glyphs, col)
 	}
-	if start > 0 && cursorIdx != 0 && len(visible) > 0 {
-		visible[0] = '~'
+
+	start, end, left, right := clipGlyphs(glyphs, max(cursorIdx, 0), width)
+	var b strings.Builder
+	if left {
+		b.WriteString("~")
 	}
-	if end < len(runes) && cursorIdx != len(visible)-1 && len(visible) > 0 {
-		visible[len(visible)-1] = '~'
+	visibleCursor := -1
+	if cursorIdx >= 0 {
+		visibleCursor = cursorIdx - start
 	}
-	return renderCursorInRunes(visible, cursorIdx, col == len(runes) && end == len(runes), width)
+	b.WriteString(renderGlyphs(glyphs[start:end], visibleCursor, hl, kinds))
+	if right {
+		b.WriteString("~")
+	} else if hl != nil && !pastEnd && glyphsWidth(glyphs[start:end]) < width && hl(runeCount) {
+		// Show highlights that sit just past the end of a short line.
+		b.WriteString(selectionStyle.Render(" "))
+	}
+	return b.String()
 }
 
-func renderCursorInRunes(runes []rune, cursorIdx int, showCursorSpace bool, width int) string {
-	var b strings.Builder
-	for i, r := range runes {
-		if i == cursorIdx {
-			b.WriteString(cursorStyle.Render(string(r)))
-			continue
+// clipGlyphs picks the glyphs[start:end] shown in width cells, keeping the
+// glyph at cursor in view and roughly centered. left and right report a
+// clipped side, which costs one cell for its marker.
+func clipGlyphs(glyphs []glyph, cursor, width int) (start, end int, left, right bool) {
+	if glyphsWidth(glyphs) <= width {
+		return 0, len(glyphs), false, false
+	}
+	if width < 3 {
+		// No room for markers next to the cursor.
+		return cursor, min(cursor+1, len(glyphs)), false, false
+	}
+	window := func(start int) int {
+		budget := width
+		if start > 0 {
+			budget--
+		}
+		if end := fitGlyphs(glyphs, start, budget); end == len(glyphs) {
+			return end
 		}
-		b.WriteRune(r)
+		return fitGlyphs(glyphs, start, budget-1)
+	}
+
+	startPos := glyphsWidth(glyphs[:min(cursor, len(glyphs))]) - width/2
+	for pos := 0; start < len(glyphs) && pos+glyphs[start].width <= startPos; start++ {
+		pos += glyphs[start].width
+	}
+	end = window(start)
+	// Near the end of the line, fill the width rather than leave it blank.
+	for start > 0 && end == len(glyphs) && window(start-1) == len(glyphs) {
+		start--
 	}
-	if cursorIdx == -1 && showCursorSpace && len(runes) < width {
-		b.WriteString(cursorStyle.Render(" "))
+	for cursor < len(glyphs) && cursor >= end && start < cursor {
+		start++
+		end = window(start)
+	}
+	return start, end, start > 0, end < len(glyphs)
+}
+
+// fitGlyphs returns the end of the longest run of glyphs from start that
+// fits in width cells.
+func fitGlyphs(glyphs []glyph, start, width int) int {
+	end, used := start, 0
+	for end < len(glyphs) && used+glyphs[end].width <= width {
+		used += glyphs[end].width
+		end++
+	}
+	return end
+}
+
+// renderGlyphs styles each glyph: the cursor wins over a highlight, which
+// wins over the kind (kinds may be nil). cursorIdx indexes glyphs; hl and
+// kinds index runes of the whole line.
+func renderGlyphs(glyphs []glyph, cursorIdx int, hl func(int) bool, kinds []tokenKind) string {
+	var b strings.Builder
+	for i, g := range glyphs {
+		switch {
+		case i == cursorIdx:
+			b.WriteString(cursorStyle.Render(g.text))
+		case hl != nil && hl(g.col):
+			b.WriteString(selectionStyle.Render(g.text))
+		case g.col < len(kinds):
+			if style, ok := syntaxStyle(kinds[g.col]); ok {
+				b.WriteString(style.Render(g.text))
+			} else {
+				b.WriteString(g.text)
+			}
+		default:
+			b.WriteString(g.text)
+		}
 	}
 	return b.String()
 }
 
+// truncateLine fits line to width display cells, ending a clipped line
+// with "~".
 func truncateLine(line string, width int) string {
 	if width <= 0 {
 		return ""
 	}
-	runes := []rune(line)
-	if len(runes) <= width {
-		return line
+	glyphs := lineGlyphs(line)
+	if glyphsWidth(glyphs) <= width {
+		return expandTabs(line)
 	}
-	if width == 1 {
-		return "~"
+	var b strings.Builder
+	end := fitGlyphs(glyphs, 0, width-1)
+	for _, g := range glyphs[:end] {
+		b.WriteString(g.text)
 	}
-	return string(runes[:width-1]) + "~"
+	// Pad when a wide glyph didn't fit in the last cells.
+	b.WriteString(strings.Repeat(" ", width-1-glyphsWidth(glyphs[:end])))
+	b.WriteString("~")
+	return b.String()
 }
 
 func windowRange(total, cursor, height int) (int, int) {
@@ -517,9 +1849,10 @@ func translateKey(msg tea.KeyMsg) string {
 }
 
 // shouldBufferKey returns true for commands that require a following key.
+// <C-w> waits for its window command (<C-w>w, <C-w>j, <C-w>v).
 func shouldBufferKey(keys string) bool {
 	switch keys {
-	case "r", "f", "t", "F", "T", "m", "'", "`", "g", "z", "@", "q", "[", "]":
+	case "r", "f", "t", "F", "T", "m", "'", "`", "g", "z", "@", "q", "[", "]", "<C-w>":
 		return true
 	}
 	return false
@@ -535,7 +1868,39 @@ func shouldStartOperator(keys string) bool {
 
 func keyNeedsChar(keys string) bool {
 	switch keys {
-	case "r", "f", "t", "F", "T":
+	case "r", "f", "t", "F", "T", "m", "`", "'", "@":
+		return true
+	}
+	return false
+}
+
+// isVisualMode reports whether mode is one of the visual modes.
+func isVisualMode(mode string) bool {
+	return mode == "VISUAL" || mode == "V-LINE" || mode == "V-BLOCK"
+}
+
+// isMarkCommand reports whether keys sets a mark (ma) or jumps to one
+// (`a, 'a). These move at most the cursor and never change mode.
+func isMarkCommand(keys string) bool {
+	keys = stripCommandPrefix(keys)
+	return len(keys) == 2 && strings.ContainsRune("m`'", rune(keys[0]))
+}
+
+// isRegisterName reports whether keys names a register a macro can be
+// recorded into.
+func isRegisterName(keys string) bool {
+	if len(keys) != 1 {
+		return false
+	}
+	c := keys[0]
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '"'
+}
+
+// isGOperatorKey reports whether keys after g make an operator (gU, gu, g~,
+// gq, gw, g?).
+func isGOperatorKey(keys string) bool {
+	switch keys {
+	case "U", "u", "~", "q", "w", "?":
 		return true
 	}
 	return false
@@ -569,7 +1934,29 @@ func (v PuzzleView) handleNvimInput(keys string) (PuzzleView, tea.Cmd) {
 		return v, nil
 	}
 
+	// Strict typing drills only accept corrections once the buffer diverges.
+	if v.strictDiverged && !isCorrectionKey(keys) {
+		return v, nil
+	}
+
+	// Undo and redo are free: the mistaken keys still count, but backing
+	// out of them costs nothing extra.
+	if v.freeUndo && v.mode == "NORMAL" && v.pendingKeys == "" && v.pendingCount == "" && isUndoKey(keys) {
+		return v, v.inputAndSync(keys)
+	}
+
+	// <Esc> cancelling a buffered prefix (d, 3, fx's f, "a) has nothing to
+	// undo: Neovim never saw the prefix, so it is neither sent nor counted,
+	// and the abandoned prefix keys are refunded.
+	if keys == "<Esc>" && v.mode == "NORMAL" && (v.pendingKeys != "" || v.pendingCount != "") {
+		v.keystrokes -= v.pendingStrokes
+		v.clearPending()
+		return v, nil
+	}
+
 	v.keystrokes++
+	v.pendingStrokes++
+	v.trackBlockInsert(keys)
 
 	// Do not buffer in insert/replace/command mode.
 	if v.mode != "NORMAL" {
@@ -580,10 +1967,12 @@ func (v PuzzleView) handleNvimInput(keys string) (PuzzleView, tea.Cmd) {
 
 	// If we have a pending prefix command, combine and send together.
 	if v.pendingKeys != "" {
-		if keys == "<Esc>" {
-			v.clearPending()
-			v.applyImmediateMode(keys)
-			return v, v.inputAndSync(keys)
+		// A register prefix ("a) then waits for a count or command like a count does.
+		if v.pendingRegister {
+			v.pendingCount = v.pendingKeys + keys
+			v.pendingKeys = ""
+			v.pendingRegister = false
+			return v, nil
 		}
 
 		if v.pendingOperator {
@@ -591,7 +1980,7 @@ func (v PuzzleView) handleNvimInput(keys string) (PuzzleView, tea.Cmd) {
 			sent := v.handleOperatorPending(keys)
 			if sent {
 				v.applyImmediateMode(combined)
-				return v, v.scheduleSync()
+				return v, v.syncAfterInput()
 			}
 			return v, nil
 		}
@@ -603,6 +1992,19 @@ func (v PuzzleView) handleNvimInput(keys string) (PuzzleView, tea.Cmd) {
 			return v, v.inputAndSync(combined)
 		}
 
+		// g followed by U, u, ~ (and friends), and zf (create fold), are
+		// operators awaiting a motion. Other z commands (zz, zt, zb, z.)
+		// are complete with their second key.
+		if strings.HasSuffix(v.pendingKeys, "g") && isGOperatorKey(keys) || strings.HasSuffix(v.pendingKeys, "z") && keys == "f" {
+			v.pendingKeys += keys
+			v.pendingOperator = true
+			return v, nil
+		}
+
+		if v.pendingKeys == "q" && isRegisterName(keys) {
+			// Show the indicator now; the next sync confirms it.
+			v.recording = keys
+		}
 		combined := v.pendingKeys + keys
 		v.clearPending()
 		v.applyImmediateMode(combined)
@@ -611,14 +2013,15 @@ func (v PuzzleView) handleNvimInput(keys string) (PuzzleView, tea.Cmd) {
 
 	// Handle leading counts (e.g. 4w, 3dw, 10fX).
 	if v.pendingCount != "" {
-		if keys == "<Esc>" {
-			v.pendingCount = ""
-			v.applyImmediateMode(keys)
-			return v, v.inputAndSync(keys)
+		if isDigitKey(keys) && (keys != "0" || endsWithDigit(v.pendingCount)) {
+			v.pendingCount += keys
+			return v, nil
 		}
 
-		if isDigitKey(keys) {
-			v.pendingCount += keys
+		if keys == `"` {
+			v.pendingKeys = v.pendingCount + keys
+			v.pendingRegister = true
+			v.pendingCount = ""
 			return v, nil
 		}
 
@@ -652,6 +2055,12 @@ func (v PuzzleView) handleNvimInput(keys string) (PuzzleView, tea.Cmd) {
 		return v, v.inputAndSync(keys)
 	}
 
+	if keys == `"` {
+		v.pendingKeys = keys
+		v.pendingRegister = true
+		return v, nil
+	}
+
 	// Buffer prefix commands that require a following key.
 	if shouldStartOperator(keys) {
 		v.pendingKeys = keys
@@ -659,6 +2068,12 @@ func (v PuzzleView) handleNvimInput(keys string) (PuzzleView, tea.Cmd) {
 		return v, nil
 	}
 
+	// While recording, q ends the macro instead of waiting for a register.
+	if keys == "q" && v.recording != "" {
+		v.recording = ""
+		return v, v.inputAndSync(keys)
+	}
+
 	if shouldBufferKey(keys) {
 		v.pendingKeys = keys
 		v.pendingNeedsChar = keyNeedsChar(keys)
@@ -669,6 +2084,38 @@ func (v PuzzleView) handleNvimInput(keys string) (PuzzleView, tea.Cmd) {
 	return v, v.inputAndSync(keys)
 }
 
+// trackBlockInsert follows a visual-block insert so the affected rows can be
+// shown while typing and highlighted once <Esc> applies it to every row.
+func (v *PuzzleView) trackBlockInsert(keys string) {
+	switch {
+	case v.mode == "V-BLOCK" && (keys == "I" || keys == "A" || keys == "c" || keys == "s"):
+		v.blockInsert = v.block != nil
+		v.blockAppend = keys == "A"
+	case v.blockInsert && keys == "<Esc>":
+		v.blockInsert = false
+		v.blockApplied = true
+	case v.blockApplied:
+		v.blockApplied = false
+		v.block = nil
+	case v.mode != "V-BLOCK" && !v.blockInsert:
+		v.block = nil
+	}
+}
+
+// isUndoKey reports whether a normal-mode key is undo or redo.
+func isUndoKey(keys string) bool {
+	return keys == "u" || keys == "<C-r>"
+}
+
+// isCorrectionKey reports whether a key can undo a mistake in a strict drill.
+func isCorrectionKey(keys string) bool {
+	switch keys {
+	case "<BS>", "<Del>", "<C-w>", "<C-u>", "<C-h>", "<Esc>", "u", "x", "X":
+		return true
+	}
+	return false
+}
+
 // applyImmediateMode updates the local mode when a key deterministically changes it.
 // This avoids misclassifying fast follow-up keys before the next nvim sync.
 func (v *PuzzleView) applyImmediateMode(keys string) {
@@ -677,12 +2124,21 @@ func (v *PuzzleView) applyImmediateMode(keys string) {
 		return
 	}
 
-	if v.mode != "NORMAL" {
+	if isVisualMode(v.mode) {
+		// I, A, c and s on a (block) selection insert into every line.
+		switch keys {
+		case "I", "A", "c", "s", "C", "S", "R":
+			v.mode = "INSERT"
+		}
 		return
 	}
 
-	switch keys {
-	case "i", "I", "a", "A", "o", "O", "s", "S", "C":
+	if v.mode != "NORMAL" || isMarkCommand(keys) {
+		return
+	}
+
+	switch stripCommandPrefix(keys) {
+	case "i", "I", "a", "A", "o", "O", "s", "S", "C", "gi", "gI":
 		v.mode = "INSERT"
 		return
 	case "R":
@@ -702,11 +2158,54 @@ func (v *PuzzleView) applyImmediateMode(keys string) {
 		return
 	}
 
-	if entersInsertAfterChange(keys) {
+	if entersInsertAfterChange(stripCommandPrefix(keys)) {
 		v.mode = "INSERT"
 	}
 }
 
+// dotRepeatText describes a normal-mode command that repeats the last change
+// (".", "3."), or returns "" for any other command.
+func dotRepeatText(keys string) string {
+	if stripCommandPrefix(keys) != "." {
+		return ""
+	}
+	count := ""
+	for i := 0; i < len(keys)-1; i++ {
+		switch {
+		case keys[i] == '"':
+			i++ // skip the register name
+		case keys[i] >= '0' && keys[i] <= '9':
+			count += keys[i : i+1]
+		}
+	}
+	if count != "" {
+		return "repeating last change ×" + count
+	}
+	return "repeating last change"
+}
+
+// stripCommandPrefix drops the leading counts and register (e.g. `3"a2`)
+// from a command, leaving the command itself.
+func stripCommandPrefix(keys string) string {
+	for keys != "" {
+		switch {
+		case keys[0] == '"' && len(keys) >= 2:
+			keys = keys[2:]
+		case keys[0] >= '0' && keys[0] <= '9':
+			keys = keys[1:]
+		default:
+			return keys
+		}
+	}
+	return keys
+}
+
+// endsWithDigit reports whether a buffered prefix ends in a count digit, so
+// a following 0 extends the count instead of being a motion.
+func endsWithDigit(prefix string) bool {
+	return prefix != "" && isDigitKey(prefix[len(prefix)-1:])
+}
+
 func entersInsertAfterChange(keys string) bool {
 	if keys == "" {
 		return false
@@ -843,7 +2342,7 @@ func (v *PuzzleView) handleOperatorPending(keys string) bool {
 	if v.pendingTextObject {
 		combined := v.pendingKeys + keys
 		v.clearPending()
-		v.nvim.Input(combined)
+		v.send(combined)
 		return true
 	}
 
@@ -851,7 +2350,7 @@ func (v *PuzzleView) handleOperatorPending(keys string) bool {
 	if v.pendingNeedsChar {
 		combined := v.pendingKeys + keys
 		v.clearPending()
-		v.nvim.Input(combined)
+		v.send(combined)
 		return true
 	}
 
@@ -859,7 +2358,7 @@ func (v *PuzzleView) handleOperatorPending(keys string) bool {
 	if len(v.pendingKeys) == 1 && keys == v.pendingKeys && !v.pendingHasCount {
 		combined := v.pendingKeys + keys
 		v.clearPending()
-		v.nvim.Input(combined)
+		v.send(combined)
 		return true
 	}
 
@@ -868,7 +2367,7 @@ func (v *PuzzleView) handleOperatorPending(keys string) bool {
 		if keys == "0" && !v.pendingHasCount {
 			combined := v.pendingKeys + keys
 			v.clearPending()
-			v.nvim.Input(combined)
+			v.send(combined)
 			return true
 		}
 		v.pendingKeys += keys
@@ -883,8 +2382,8 @@ func (v *PuzzleView) handleOperatorPending(keys string) bool {
 		return false
 	}
 
-	// Motions requiring a character (dfx)
-	if isMotionCharPrefix(keys) {
+	// Motions requiring a second key (dfx, dgg)
+	if isMotionCharPrefix(keys) || keys == "g" {
 		v.pendingKeys += keys
 		v.pendingNeedsChar = true
 		return false
@@ -892,10 +2391,15 @@ func (v *PuzzleView) handleOperatorPending(keys string) bool {
 
 	combined := v.pendingKeys + keys
 	v.clearPending()
-	v.nvim.Input(combined)
+	v.send(combined)
 	return true
 }
 
+// hasPending reports whether a count or partial command is buffered.
+func (v PuzzleView) hasPending() bool {
+	return v.pendingKeys != "" || v.pendingCount != ""
+}
+
 func (v *PuzzleView) clearPending() {
 	v.pendingKeys = ""
 	v.pendingOperator = false
@@ -903,41 +2407,51 @@ func (v *PuzzleView) clearPending() {
 	v.pendingTextObject = false
 	v.pendingHasCount = false
 	v.pendingCount = ""
+	v.pendingRegister = false
+	v.pendingStrokes = 0
 }
 
+// scheduleSync queues a sync shortly after input. Keys typed while one is
+// already queued are picked up by it, so a burst of input costs one sync.
 func (v *PuzzleView) scheduleSync() tea.Cmd {
+	if v.syncQueued {
+		return nil
+	}
+	v.syncQueued = true
 	return tea.Tick(10*time.Millisecond, func(time.Time) tea.Msg {
 		return nvimSyncMsg{}
 	})
 }
 
-func (v *PuzzleView) inputAndSync(keys string) tea.Cmd {
-	if v.nvim != nil {
-		v.nvim.Input(keys)
+// syncAfterInput schedules the sync that shows the effect of input. When
+// Neovim pushes its changes, the sync comes from the notification instead.
+func (v *PuzzleView) syncAfterInput() tea.Cmd {
+	if v.nvim != nil && v.nvim.LiveUpdates() {
+		return nil
 	}
 	return v.scheduleSync()
 }
 
-// renderBufferWithHighlight applies diff highlighting compared to goal.
-func renderBufferWithHighlight(lines []string, goalText string, cursorRow, cursorCol int) string {
-	goalLines := strings.Split(goalText, "\n")
-	var rendered []string
-
-	for i, line := range lines {
-		var goalLine string
-		if i < len(goalLines) {
-			goalLine = goalLines[i]
-		}
+func (v *PuzzleView) inputAndSync(keys string) tea.Cmd {
+	v.send(keys)
+	return v.syncAfterInput()
+}
 
-		if line == goalLine {
-			// Line matches goal - render normally
-			rendered = append(rendered, line)
-		} else {
-			// Line differs - render with dim
-			style := lipgloss.NewStyle().Foreground(colorWarning)
-			rendered = append(rendered, style.Render(line))
+// send forwards a complete command to Neovim and records it in the key log.
+func (v *PuzzleView) send(keys string) {
+	v.goalSeen = false
+	v.repeatNote = ""
+	v.pendingStrokes = 0
+	if v.mode == "NORMAL" {
+		v.repeatNote = dotRepeatText(keys)
+	}
+	v.keyLog = append(v.keyLog, keys)
+	if len(v.keyLog) > maxKeyLog {
+		v.keyLog = v.keyLog[len(v.keyLog)-maxKeyLog:]
+	}
+	if v.nvim != nil {
+		if err := v.nvim.Input(keys); err != nil {
+			v.noteNvimError(err)
 		}
 	}
-
-	return strings.Join(rendered, "\n")
 }
@@ -0,0 +1,1576 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vimgym/vimgym/internal/progress"
+	"github.com/vimgym/vimgym/internal/puzzle"
+)
+
+func testPuzzle() puzzle.Puzzle {
+	return puzzle.Puzzle{
+		ID:     "test-01",
+		Title:  "Test",
+		Level:  1,
+		Before: puzzle.BeforeState{Text: "hello world"},
+		After:  puzzle.AfterState{Text: "world"},
+		Par:    2,
+	}
+}
+
+func TestLoadAndResetDoNotCountKeystrokes(t *testing.T) {
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+
+	v, _ = v.Update(initPuzzleMsg{})
+	if v.keystrokes != 0 {
+		t.Fatalf("after load: keystrokes = %d, want 0", v.keystrokes)
+	}
+
+	v, _ = v.handleNvimInput("x")
+	v, _ = v.handleNvimInput("x")
+	if v.keystrokes != 2 {
+		t.Fatalf("after typing: keystrokes = %d, want 2", v.keystrokes)
+	}
+
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyF5})
+	if v.keystrokes != 0 {
+		t.Errorf("after reset: keystrokes = %d, want 0", v.keystrokes)
+	}
+}
+
+func TestClockStartsOnFirstKeyAndStopsOnExit(t *testing.T) {
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+	v, _ = v.Update(initPuzzleMsg{})
+	if v.timerStarted {
+		t.Fatal("timer started before any keystroke")
+	}
+
+	v, _ = v.handleKey("x")
+	if !v.timerStarted || v.clockID == 0 {
+		t.Fatal("timer not started by first keystroke")
+	}
+	id := v.clockID
+	if _, cmd := v.Update(clockTickMsg{id: id}); cmd == nil {
+		t.Error("live tick was not rescheduled")
+	}
+
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlQ})
+	if _, cmd := v.Update(clockTickMsg{id: id}); cmd != nil {
+		t.Error("tick rescheduled after leaving the puzzle")
+	}
+}
+
+func TestClockWaitsForCountedKey(t *testing.T) {
+	v := NewPuzzleView(testPuzzle(), &fakeNvim{}, nil, nil)
+	v, _ = v.Update(initPuzzleMsg{})
+
+	// Undo is free, so the clock waits for a key that counts.
+	v, _ = v.handleKey("u")
+	if v.timerStarted || v.keystrokes != 0 {
+		t.Fatalf("timer started %v with %d keystrokes; want neither", v.timerStarted, v.keystrokes)
+	}
+
+	// A key typed while reading the solution waits for it to close.
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	v, _ = v.handleKey("x")
+	if !v.timerStarted || v.clockID != 0 || !v.timerStart.IsZero() {
+		t.Fatal("clock running while the solution is open")
+	}
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	if v.clockID == 0 || v.timerStart.IsZero() {
+		t.Error("clock not started when the solution closed")
+	}
+}
+
+func TestKeyLogCollapsesBufferedCommands(t *testing.T) {
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+	for _, k := range []string{"d", "w", "x", "3", "j"} {
+		v, _ = v.handleNvimInput(k)
+	}
+	want := []string{"dw", "x", "3j"}
+	if len(v.keyLog) != len(want) {
+		t.Fatalf("keyLog = %q, want %q", v.keyLog, want)
+	}
+	for i := range want {
+		if v.keyLog[i] != want[i] {
+			t.Errorf("keyLog[%d] = %q, want %q", i, v.keyLog[i], want[i])
+		}
+	}
+
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyF5})
+	if len(v.keyLog) != 0 {
+		t.Errorf("keyLog after reset = %q, want empty", v.keyLog)
+	}
+}
+
+func TestShowcmdTracksPendingKeys(t *testing.T) {
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+	steps := []struct {
+		key  string
+		want string
+	}{
+		{"3", "3"},
+		{"d", "3d"},
+		{"w", ""},
+		{"f", "f"},
+		{"<Esc>", ""},
+	}
+	for _, st := range steps {
+		v, _ = v.handleNvimInput(st.key)
+		if got := v.showcmd(); got != st.want {
+			t.Errorf("after %q: showcmd = %q, want %q", st.key, got, st.want)
+		}
+	}
+}
+
+func TestUndoAndRedoAreFree(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []string
+		freeUndo bool
+		want     int
+	}{
+		{"undo after change", []string{"x", "u"}, true, 1},
+		{"redo after undo", []string{"x", "u", "<C-r>"}, true, 1},
+		{"undo counted when disabled", []string{"x", "u", "<C-r>"}, false, 3},
+		{"u in insert mode is text", []string{"i", "u", "<Esc>"}, true, 3},
+		{"u after count is counted", []string{"2", "u"}, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+			v.freeUndo = tt.freeUndo
+			for _, k := range tt.keys {
+				v, _ = v.handleNvimInput(k)
+			}
+			if v.keystrokes != tt.want {
+				t.Errorf("keystrokes = %d, want %d", v.keystrokes, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountsToggle(t *testing.T) {
+	nv := &fakeNvim{lines: []string{"hello world"}}
+	v := NewPuzzleView(testPuzzle(), nv, nil, nil)
+	v.width, v.height = 100, 40
+	v, _ = v.Update(initPuzzleMsg{})
+	if strings.Contains(v.View(), "Words:") {
+		t.Fatal("counts shown before the toggle")
+	}
+
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyF2})
+	if !strings.Contains(v.View(), "Words: 2/1  Chars: 11/5") {
+		t.Errorf("counts of the loaded buffer missing:\n%s", v.View())
+	}
+	nv.lines = []string{"wor", "ld"}
+	v, _ = v.Update(nvimSyncMsg{})
+	if !strings.Contains(v.View(), "Words: 2/1  Chars: 5/5") {
+		t.Errorf("counts not updated from the synced lines:\n%s", v.View())
+	}
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyF2})
+	if strings.Contains(v.View(), "Words:") {
+		t.Error("counts still shown after toggling off")
+	}
+
+	// Ctrl+K is Vim's digraph key and goes to Neovim.
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlK})
+	if v.showCounts || nv.sent[len(nv.sent)-1] != "<C-k>" {
+		t.Errorf("Ctrl+K: counts %v, sent %q; want it passed to Neovim", v.showCounts, nv.sent)
+	}
+}
+
+func TestVimCtrlKeysReachNeovim(t *testing.T) {
+	tests := []struct {
+		key  tea.KeyType
+		want string
+	}{
+		{tea.KeyCtrlN, "<C-n>"},
+		{tea.KeyCtrlL, "<C-l>"},
+		{tea.KeyCtrlG, "<C-g>"},
+	}
+	for _, tt := range tests {
+		nv := &fakeNvim{}
+		v := NewPuzzleView(testPuzzle(), nv, nil, nil)
+		v, _ = v.Update(initPuzzleMsg{})
+		before := v
+		v, _ = v.Update(tea.KeyMsg{Type: tt.key})
+		if len(nv.sent) != 1 || nv.sent[0] != tt.want {
+			t.Errorf("%s: sent %q, want %q", tt.want, nv.sent, tt.want)
+		}
+		if v.revealedSteps != before.revealedSteps || v.showKeyLog != before.showKeyLog || v.ghost != before.ghost {
+			t.Errorf("%s toggled a VimGym feature", tt.want)
+		}
+	}
+
+	p := testPuzzle()
+	p.OptimalSolution = "xx"
+	v := NewPuzzleView(p, &fakeNvim{}, nil, nil)
+	v, _ = v.Update(initPuzzleMsg{})
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyF3})
+	if v.revealedSteps != 1 {
+		t.Errorf("F3 revealed %d solution keys, want 1", v.revealedSteps)
+	}
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyF4})
+	if !v.showKeyLog {
+		t.Error("F4 did not show the key log")
+	}
+}
+
+func TestCtrlRIsRedoNotReset(t *testing.T) {
+	nv := &fakeNvim{}
+	v := NewPuzzleView(testPuzzle(), nv, nil, nil)
+	v, _ = v.Update(initPuzzleMsg{})
+	for _, k := range []string{"x", "u"} {
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+	}
+	attempt := v.attempt
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if v.attempt != attempt {
+		t.Fatal("Ctrl+R reset the puzzle")
+	}
+	if v.keystrokes != 1 {
+		t.Errorf("keystrokes = %d, want 1: undo and redo are free", v.keystrokes)
+	}
+	if want := []string{"x", "u", "<C-r>"}; !reflect.DeepEqual(nv.sent, want) {
+		t.Errorf("sent %q, want %q", nv.sent, want)
+	}
+
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyF5})
+	if v.attempt != attempt+1 || v.keystrokes != 0 {
+		t.Errorf("F5: attempt %d, keystrokes %d; want a fresh attempt", v.attempt, v.keystrokes)
+	}
+}
+
+func TestEmptyGoalNotClearedBeforeInput(t *testing.T) {
+	p := testPuzzle()
+	p.Before.Text = ""
+	p.After.Text = ""
+	v := NewPuzzleView(p, nil, nil, nil)
+	v, _ = v.Update(initPuzzleMsg{})
+
+	v.checkClear("")
+	if v.state != statePlaying {
+		t.Fatal("empty goal cleared before any keystroke")
+	}
+
+	v, _ = v.handleNvimInput("x")
+	v.checkClear("")
+	v.checkClear("")
+	if v.state != stateCleared {
+		t.Error("empty goal not cleared after input emptied the buffer")
+	}
+}
+
+func TestBlockInsertHighlight(t *testing.T) {
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+	v.mode = "V-BLOCK"
+	v.block = &blockSelection{startRow: 0, startCol: 2, endRow: 2, endCol: 4}
+
+	hl := v.lineHighlight(1)
+	if hl == nil || !hl(3) || hl(5) {
+		t.Fatal("block selection not highlighted on row 1 cols 2-4")
+	}
+	if v.lineHighlight(3) != nil {
+		t.Error("row outside block highlighted")
+	}
+
+	v, _ = v.handleNvimInput("I")
+	v.mode = "INSERT"
+	if hl := v.lineHighlight(2); hl == nil || !hl(2) || hl(3) {
+		t.Error("block insert column not marked while typing")
+	}
+
+	v, _ = v.handleNvimInput("<Esc>")
+	if !v.blockApplied || v.lineHighlight(2) == nil {
+		t.Error("block rows not highlighted after <Esc>")
+	}
+
+	v, _ = v.handleNvimInput("j")
+	if v.lineHighlight(2) != nil {
+		t.Error("block highlight kept after the next key")
+	}
+}
+
+func TestMultiStepCheckpoint(t *testing.T) {
+	p := testPuzzle()
+	p.Before.Text = "a b c"
+	p.Steps = []puzzle.AfterState{{Text: "b c"}}
+	p.After.Text = "c"
+	v := NewPuzzleView(p, nil, nil, nil)
+	v, _ = v.Update(initPuzzleMsg{})
+
+	v, _ = v.handleNvimInput("x")
+	v, _ = v.handleNvimInput("x")
+	v.checkClear("b c")
+	if v.stepIndex != 1 || v.checkpoint == nil {
+		t.Fatalf("step not completed: stepIndex=%d", v.stepIndex)
+	}
+	if v.currentGoal() != "c" {
+		t.Errorf("currentGoal = %q, want final goal", v.currentGoal())
+	}
+
+	v, _ = v.handleNvimInput("x")
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyF5})
+	if v.keystrokes != 2 || v.stepIndex != 1 {
+		t.Errorf("after checkpoint reset: keystrokes=%d stepIndex=%d, want 2 and 1", v.keystrokes, v.stepIndex)
+	}
+
+	v.checkClear("c")
+	v.checkClear("c")
+	if v.state != stateCleared {
+		t.Error("final goal did not clear the puzzle")
+	}
+}
+
+func TestClearNeedsGoalOnTwoSyncs(t *testing.T) {
+	p := testPuzzle()
+	v := NewPuzzleView(p, nil, nil, nil)
+	v, _ = v.Update(initPuzzleMsg{})
+	v, _ = v.handleNvimInput("x")
+
+	v.checkClear(p.After.Text)
+	if v.state != statePlaying {
+		t.Fatal("cleared on the first matching sync")
+	}
+	v.checkClear(p.Before.Text)
+	v.checkClear(p.After.Text)
+	if v.state != statePlaying {
+		t.Fatal("cleared although the goal was not matched on consecutive syncs")
+	}
+
+	v, _ = v.handleNvimInput("x")
+	v.checkClear(p.After.Text)
+	if v.state != statePlaying {
+		t.Fatal("match before a new key carried over past it")
+	}
+	v.checkClear(p.After.Text)
+	if v.state != stateCleared {
+		t.Error("not cleared after two consecutive matching syncs")
+	}
+}
+
+func TestNoClearWhileCommandPending(t *testing.T) {
+	p := testPuzzle()
+	v := NewPuzzleView(p, nil, nil, nil)
+	v, _ = v.Update(initPuzzleMsg{})
+
+	// A sync from an earlier key lands after "d" has been buffered.
+	v, _ = v.handleNvimInput("x")
+	v, _ = v.handleNvimInput("d")
+	v.checkClear(p.After.Text)
+	v.checkClear(p.After.Text)
+	if v.state != statePlaying {
+		t.Fatal("cleared while an operator was pending")
+	}
+
+	v, _ = v.handleNvimInput("<Esc>")
+	v.checkClear(p.After.Text)
+	v.checkClear(p.After.Text)
+	if v.state != stateCleared {
+		t.Error("not cleared once the pending operator was cancelled")
+	}
+}
+
+func TestCopySolutionOnClearScreen(t *testing.T) {
+	var out bytes.Buffer
+	clipboardOut = &out
+	t.Cleanup(func() { clipboardOut = os.Stdout })
+
+	p := testPuzzle()
+	p.OptimalSolution = "dw"
+	v := NewPuzzleView(p, nil, nil, nil)
+	v.state = stateCleared
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+
+	want := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("dw")) + "\x07"
+	if out.String() != want {
+		t.Errorf("clipboard output = %q, want %q", out.String(), want)
+	}
+	if !strings.Contains(v.View(), "copied") {
+		t.Error("clear screen does not confirm the copy")
+	}
+
+	out.Reset()
+	solutionsDisabled = true
+	t.Cleanup(func() { solutionsDisabled = false })
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if out.Len() != 0 {
+		t.Error("solution copied while solutions are disabled")
+	}
+}
+
+func TestVisualSelectionHighlight(t *testing.T) {
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+	v.lines = []string{"abcdef", "ghijkl", "mnopqr"}
+	v.mode = "VISUAL"
+	v.selection = &visualSelection{startRow: 0, startCol: 3, endRow: 2, endCol: 1}
+
+	if hl := v.lineHighlight(0); hl == nil || hl(2) || !hl(3) || !hl(6) {
+		t.Error("charwise start row should highlight from the start column to the line end")
+	}
+	if hl := v.lineHighlight(1); hl == nil || !hl(0) || !hl(5) {
+		t.Error("charwise middle row should be fully highlighted")
+	}
+	if hl := v.lineHighlight(2); hl == nil || !hl(1) || hl(2) {
+		t.Error("charwise end row should stop at the end column")
+	}
+
+	v.mode = "V-LINE"
+	v.selection = &visualSelection{startRow: 1, endRow: 1, linewise: true}
+	if v.lineHighlight(0) != nil {
+		t.Error("row outside a linewise selection highlighted")
+	}
+	if hl := v.lineHighlight(1); hl == nil || !hl(0) || !hl(5) {
+		t.Error("linewise selection should cover the whole row")
+	}
+
+	v.mode = "NORMAL"
+	if v.lineHighlight(1) != nil {
+		t.Error("stale selection highlighted outside visual mode")
+	}
+}
+
+func TestRuneCol(t *testing.T) {
+	lines := []string{"héllo"}
+	if got := runeCol(lines, 0, 3); got != 2 {
+		t.Errorf("runeCol byte 3 = %d, want 2", got)
+	}
+	if got := runeCol(lines, 5, 3); got != 3 {
+		t.Errorf("runeCol out-of-range row = %d, want byte col unchanged", got)
+	}
+}
+
+func TestAutoAdvanceAfterClear(t *testing.T) {
+	p := testPuzzle()
+	v := NewPuzzleView(p, nil, nil, nil)
+	v.settings.AutoAdvance = true
+	v, _ = v.Update(initPuzzleMsg{})
+	v, _ = v.handleNvimInput("x")
+	v.checkClear(p.After.Text)
+	v.checkClear(p.After.Text)
+	if v.state != stateCleared {
+		t.Fatal("puzzle not cleared")
+	}
+	if v.autoAdvanceCmd() == nil {
+		t.Fatal("no auto-advance scheduled after a clear")
+	}
+
+	_, cmd := v.Update(autoAdvanceMsg{attempt: v.attempt - 1})
+	if cmd != nil {
+		t.Error("stale auto-advance tick acted on a newer attempt")
+	}
+	_, cmd = v.Update(autoAdvanceMsg{attempt: v.attempt})
+	if cmd == nil {
+		t.Fatal("auto-advance did not leave the clear screen")
+	}
+	if msg, ok := cmd().(puzzleExitMsg); !ok || !msg.next {
+		t.Errorf("auto-advance emitted %#v, want puzzleExitMsg{next: true}", msg)
+	}
+
+	v.settings.AutoAdvance = false
+	if v.autoAdvanceCmd() != nil {
+		t.Error("auto-advance scheduled with the setting off")
+	}
+}
+
+func TestRegisterText(t *testing.T) {
+	tests := []struct {
+		content string
+		width   int
+		want    string
+	}{
+		{"", 40, `Register "": (empty)`},
+		{"foo", 40, `Register "": foo`},
+		{"line one\nline two\n", 40, `Register "": line one⏎line two⏎`},
+		{"abcdefghij", 18, `Register "": abcd…`},
+	}
+	for _, tt := range tests {
+		if got := registerText(tt.content, tt.width); got != tt.want {
+			t.Errorf("registerText(%q, %d) = %q, want %q", tt.content, tt.width, got, tt.want)
+		}
+	}
+
+	p := testPuzzle()
+	p.Category = "yank-paste"
+	v := NewPuzzleView(p, nil, nil, nil)
+	v.width, v.height = 80, 40
+	if !strings.Contains(v.View(), `Register "": (empty)`) {
+		t.Error("yank-paste puzzle does not show the register line")
+	}
+	if strings.Contains(NewPuzzleView(testPuzzle(), nil, nil, nil).View(), `Register ""`) {
+		t.Error("register line shown on a non-yank puzzle")
+	}
+}
+
+func TestMacroRecordingIndicator(t *testing.T) {
+	if got := recordingText(""); got != "" {
+		t.Errorf(`recordingText("") = %q, want ""`, got)
+	}
+	if got := recordingText("a"); got != "recording @a" {
+		t.Errorf(`recordingText("a") = %q, want "recording @a"`, got)
+	}
+
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+	v.width, v.height = 80, 40
+	v, _ = v.Update(initPuzzleMsg{})
+	v, _ = v.handleNvimInput("q")
+	v, _ = v.handleNvimInput("a")
+	if v.recording != "a" || !strings.Contains(v.View(), "recording @a") {
+		t.Fatalf("after qa: recording %q, want the status line to show recording @a", v.recording)
+	}
+
+	// The terminating q is sent at once rather than waiting for a register.
+	v, _ = v.handleNvimInput("x")
+	v, _ = v.handleNvimInput("q")
+	if v.recording != "" || v.pendingKeys != "" {
+		t.Errorf("after the closing q: recording %q, pending %q; want neither", v.recording, v.pendingKeys)
+	}
+	if strings.Contains(v.View(), "recording @") {
+		t.Error("recording indicator still shown after q")
+	}
+}
+
+func TestSandboxNeverClears(t *testing.T) {
+	prog, err := progress.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := puzzle.Sandbox()
+	v := NewPuzzleView(p, nil, prog, nil)
+	v.width, v.height = 80, 40
+	v, _ = v.Update(initPuzzleMsg{})
+	v, _ = v.handleNvimInput("x")
+	v.checkClear(p.After.Text)
+	v.checkClear(p.After.Text)
+	if v.state != statePlaying {
+		t.Error("sandbox cleared")
+	}
+	if got := prog.GetBest(p.ID).Attempts; got != 0 {
+		t.Errorf("sandbox recorded %d attempts, want none", got)
+	}
+	view := v.View()
+	if strings.Contains(view, "GOAL") || strings.Contains(view, "par:") || !strings.Contains(view, "EDITOR") {
+		t.Errorf("sandbox view should show the editor without a goal or par:\n%s", view)
+	}
+}
+
+func TestAttemptsCounted(t *testing.T) {
+	dir := t.TempDir()
+	prog, err := progress.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := testPuzzle()
+	v := NewPuzzleView(p, nil, prog, nil)
+	v, _ = v.Update(initPuzzleMsg{})
+	if got := prog.GetBest(p.ID).Attempts; got != 1 {
+		t.Errorf("attempts after loading = %d, want 1", got)
+	}
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyF5})
+	if got := prog.GetBest(p.ID).Attempts; got != 2 {
+		t.Errorf("attempts after a reset = %d, want 2", got)
+	}
+
+	reloaded, err := progress.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.GetBest(p.ID).Attempts; got != 2 {
+		t.Errorf("attempts after reloading = %d, want 2", got)
+	}
+	v = NewPuzzleView(p, nil, reloaded, nil)
+	v.Update(initPuzzleMsg{})
+	if got := reloaded.GetBest(p.ID).Attempts; got != 3 {
+		t.Errorf("attempts after reopening the puzzle = %d, want 3", got)
+	}
+}
+
+func TestHintPenalty(t *testing.T) {
+	p := testPuzzle()
+	solve := func(v PuzzleView) PuzzleView {
+		v, _ = v.handleNvimInput("x")
+		v, _ = v.handleNvimInput("x")
+		v.checkClear(p.After.Text)
+		v.checkClear(p.After.Text)
+		return v
+	}
+
+	v := NewPuzzleView(p, nil, nil, nil)
+	v, _ = v.Update(initPuzzleMsg{})
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlH})
+	if v = solve(v); v.stars != puzzle.TwoStar {
+		t.Errorf("at par with a hint: %v, want two stars", v.stars)
+	}
+	if !strings.Contains(v.View(), "Hint used") {
+		t.Error("clear screen doesn't mention the hint penalty")
+	}
+
+	// F5 starts a fresh attempt without the penalty.
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyF5})
+	if v.hintUsed {
+		t.Fatal("hint flag survived a reset")
+	}
+	if v = solve(v); v.stars != puzzle.ThreeStar {
+		t.Errorf("at par without help: %v, want three stars", v.stars)
+	}
+
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyF5})
+	v.settings.FreeHints = true
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlH})
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	if v = solve(v); v.stars != puzzle.ThreeStar {
+		t.Errorf("with free hints: %v, want three stars", v.stars)
+	}
+}
+
+func TestSolutionSteps(t *testing.T) {
+	tests := []struct {
+		solution string
+		want     []solutionStep
+	}{
+		{`ci"x<Esc>`, []solutionStep{{`ci"x<Esc>`, `change inside quotes, typing "x"`}}},
+		{"3ddp", []solutionStep{{"3dd", "delete the line ×3"}, {"p", "put after the cursor"}}},
+		{"/beta<CR>ncwzeta<Esc>", []solutionStep{
+			{"/beta<CR>", `search forward for "beta"`},
+			{"n", "move to the next match"},
+			{"cwzeta<Esc>", `change to the next word, typing "zeta"`},
+		}},
+		{"3JVr,", []solutionStep{
+			{"3J", "join the line below ×3"},
+			{"V", "start visual line mode"},
+			{"r,", `replace every selected character with ","`},
+		}},
+		{"qaA;<Esc>jq2@a", []solutionStep{
+			{"qa", "record a macro into register a"},
+			{"A;<Esc>", `append at the end of the line, typing ";"`},
+			{"j", "move down"},
+			{"q", "stop recording the macro"},
+			{"2@a", "run the macro in register a ×2"},
+		}},
+		{"0gUiwf,D", []solutionStep{
+			{"0", "move to the start of the line"},
+			{"gUiw", "uppercase inside word"},
+			{"f,", `move to the next ","`},
+			{"D", "delete to the end of the line"},
+		}},
+		{":%s/a/b/g<CR>", []solutionStep{{":%s/a/b/g<CR>", "run the Ex command :%s/a/b/g"}}},
+	}
+	for _, tt := range tests {
+		got := solutionSteps(tt.solution)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("solutionSteps(%q) =\n%q\nwant\n%q", tt.solution, got, tt.want)
+		}
+	}
+}
+
+func TestSolutionWalkthrough(t *testing.T) {
+	p := testPuzzle()
+	p.OptimalSolution = "dw"
+	p.Before.Text = "hello world"
+	v := NewPuzzleView(p, nil, nil, nil)
+	v, _ = v.Update(initPuzzleMsg{})
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	v, _ = v.Update(space)
+	if v.keystrokes != 0 {
+		t.Errorf("stepping the solution counted %d keystrokes", v.keystrokes)
+	}
+	if !strings.Contains(v.View(), "Step 1/1: dw — delete to the next word") {
+		t.Errorf("walkthrough step not shown:\n%s", v.View())
+	}
+
+	// Closed, space goes to Neovim again.
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	v, _ = v.Update(space)
+	if v.keystrokes != 1 {
+		t.Errorf("space with the solution closed: %d keystrokes, want 1", v.keystrokes)
+	}
+}
+
+func TestSolutionWalkthroughLeavesTypedSpaces(t *testing.T) {
+	p := testPuzzle()
+	p.OptimalSolution = "dw"
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	tests := []struct {
+		name  string
+		setup func(PuzzleView) PuzzleView
+		sent  []string
+	}{
+		{"insert mode", func(v PuzzleView) PuzzleView { v.mode = "INSERT"; return v }, []string{" "}},
+		{"pending operator", func(v PuzzleView) PuzzleView { v, _ = v.handleNvimInput("d"); return v }, []string{"d "}},
+		{"pending count", func(v PuzzleView) PuzzleView { v, _ = v.handleNvimInput("3"); return v }, []string{"3 "}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nv := &fakeNvim{}
+			v := NewPuzzleView(p, nv, nil, nil)
+			v, _ = v.Update(initPuzzleMsg{})
+			v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+			v = tt.setup(v)
+			v, _ = v.Update(space)
+			if v.walkthrough != 0 {
+				t.Error("space stepped the solution")
+			}
+			if !reflect.DeepEqual(nv.sent, tt.sent) {
+				t.Errorf("sent %q, want %q", nv.sent, tt.sent)
+			}
+		})
+	}
+}
+
+func TestDemoLeavesAttemptUnscored(t *testing.T) {
+	p := testPuzzle()
+	p.OptimalSolution = "dw"
+	v := NewPuzzleView(p, nil, nil, nil)
+	v, _ = v.Update(initPuzzleMsg{})
+	v, _ = v.handleNvimInput("x")
+	v.checkClear(p.After.Text)
+	v.checkClear(p.After.Text)
+	keystrokes, stars, log := v.keystrokes, v.stars, len(v.keyLog)
+
+	v, cmd := v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if v.state != stateDemo || cmd == nil {
+		t.Fatal("d on the clear screen did not start the demo")
+	}
+	v, cmd = v.Update(demoStepMsg{id: v.demoID})
+	if cmd == nil || v.demoStep != 1 {
+		t.Fatalf("demo did not play its first command (step %d)", v.demoStep)
+	}
+	if !strings.Contains(v.View(), "DEMO (not scored) 1/1: dw") {
+		t.Errorf("demo not marked as such:\n%s", v.View())
+	}
+	v, cmd = v.Update(demoStepMsg{id: v.demoID})
+	if cmd != nil {
+		t.Error("demo kept ticking after its last command")
+	}
+	if v.keystrokes != keystrokes || v.stars != stars || len(v.keyLog) != log {
+		t.Error("demo changed the scored attempt")
+	}
+
+	if _, cmd := v.Update(demoStepMsg{id: v.demoID - 1}); cmd != nil {
+		t.Error("stale demo tick acted")
+	}
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if v.state != stateCleared {
+		t.Error("esc did not leave the demo")
+	}
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if v.state != statePlaying || v.keystrokes != 0 {
+		t.Error("r during the demo did not start a fresh attempt")
+	}
+}
+
+func TestRegisterPrefixPending(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    []string
+		showcmd []string // after each key
+		sent    []string
+	}{
+		{"yank into register", []string{`"`, "a", "y", "y"}, []string{`"`, `"a`, `"ay`, ""}, []string{`"ayy`}},
+		{"register then count", []string{`"`, "a", "3", "y", "y"}, []string{`"`, `"a`, `"a3`, `"a3y`, ""}, []string{`"a3yy`}},
+		{"count then register", []string{"2", `"`, "b", "p"}, []string{"2", `2"`, `2"b`, ""}, []string{`2"bp`}},
+		{"register with text object", []string{`"`, "a", "d", "i", "w"}, []string{`"`, `"a`, `"ad`, `"adi`, ""}, []string{`"adiw`}},
+		{"register then 0 motion", []string{`"`, "a", "0"}, []string{`"`, `"a`, ""}, []string{`"a0`}},
+		{"esc cancels register", []string{`"`, "<Esc>", "x"}, []string{`"`, "", ""}, []string{"x"}},
+		{"esc cancels after name", []string{`"`, "a", "<Esc>", "x"}, []string{`"`, `"a`, "", ""}, []string{"x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+			for i, k := range tt.keys {
+				v, _ = v.handleNvimInput(k)
+				if got := v.showcmd(); got != tt.showcmd[i] {
+					t.Errorf("after %q: showcmd = %q, want %q", k, got, tt.showcmd[i])
+				}
+			}
+			if !reflect.DeepEqual(v.keyLog, tt.sent) {
+				t.Errorf("sent %q, want %q", v.keyLog, tt.sent)
+			}
+			if v.pendingRegister {
+				t.Error("register still pending")
+			}
+		})
+	}
+
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+	for _, k := range []string{`"`, "a", "c", "w"} {
+		v, _ = v.handleNvimInput(k)
+	}
+	if v.mode != "INSERT" {
+		t.Errorf(`mode after "acw = %q, want INSERT`, v.mode)
+	}
+}
+
+func TestGPrefixCommands(t *testing.T) {
+	tests := []struct {
+		keys []string
+		sent string
+	}{
+		{[]string{"g", "g"}, "gg"},
+		{[]string{"g", "U", "$"}, "gU$"},
+		{[]string{"g", "u", "i", "w"}, "guiw"},
+		{[]string{"g", "~", "~"}, "g~~"},
+		{[]string{"g", "U", "U"}, "gUU"},
+		{[]string{"2", "g", "u", "u"}, "2guu"},
+		{[]string{"g", "U", "f", "x"}, "gUfx"},
+		{[]string{"d", "g", "g"}, "dgg"},
+	}
+	for _, tt := range tests {
+		v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+		for i, k := range tt.keys {
+			v, _ = v.handleNvimInput(k)
+			if i < len(tt.keys)-1 && len(v.keyLog) != 0 {
+				t.Errorf("%s: sent %q before the command was complete", tt.sent, v.keyLog)
+			}
+		}
+		if len(v.keyLog) != 1 || v.keyLog[0] != tt.sent || v.hasPending() {
+			t.Errorf("%s: sent %q (pending %q), want one command", tt.sent, v.keyLog, v.showcmd())
+		}
+		if v.keystrokes != len(tt.keys) {
+			t.Errorf("%s: keystrokes = %d, want %d", tt.sent, v.keystrokes, len(tt.keys))
+		}
+		if v.mode != "NORMAL" {
+			t.Errorf("%s: mode = %s, want NORMAL", tt.sent, v.mode)
+		}
+	}
+}
+
+func TestDotRepeat(t *testing.T) {
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+	for _, k := range []string{"x", "3", "."} {
+		v, _ = v.handleNvimInput(k)
+	}
+	if want := []string{"x", "3."}; !reflect.DeepEqual(v.keyLog, want) {
+		t.Errorf("sent %q, want %q", v.keyLog, want)
+	}
+	if v.keystrokes != 3 {
+		t.Errorf("keystrokes = %d, want 3", v.keystrokes)
+	}
+	if v.repeatNote != "repeating last change ×3" || !strings.Contains(v.View(), "repeating last change ×3") {
+		t.Errorf("repeat note = %q", v.repeatNote)
+	}
+
+	v, _ = v.handleNvimInput(".")
+	if v.repeatNote != "repeating last change" {
+		t.Errorf("repeat note after . = %q", v.repeatNote)
+	}
+	v, _ = v.handleNvimInput("w")
+	if v.repeatNote != "" {
+		t.Errorf("repeat note kept after another command: %q", v.repeatNote)
+	}
+
+	// In insert mode "." is just text.
+	v, _ = v.handleNvimInput("i")
+	v, _ = v.handleNvimInput(".")
+	if v.repeatNote != "" {
+		t.Errorf("typed . flagged as a repeat: %q", v.repeatNote)
+	}
+}
+
+func TestZCommands(t *testing.T) {
+	for _, second := range []string{"z", "t", "b", "."} {
+		v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+		v, _ = v.handleNvimInput("z")
+		if len(v.keyLog) != 0 || v.showcmd() != "z" {
+			t.Fatalf("z was not buffered (sent %q)", v.keyLog)
+		}
+		v, _ = v.handleNvimInput(second)
+		if want := "z" + second; len(v.keyLog) != 1 || v.keyLog[0] != want {
+			t.Errorf("sent %q, want %q", v.keyLog, want)
+		}
+		if v.hasPending() || v.pendingKeys != "" {
+			t.Errorf("z%s left pending keys %q", second, v.showcmd())
+		}
+	}
+
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+	for _, k := range []string{"z", "f", "j"} {
+		v, _ = v.handleNvimInput(k)
+	}
+	if len(v.keyLog) != 1 || v.keyLog[0] != "zfj" || v.hasPending() {
+		t.Errorf("zfj sent as %q (pending %q), want one command", v.keyLog, v.showcmd())
+	}
+}
+
+func TestMarkCommands(t *testing.T) {
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+	for _, k := range []string{"m", "a", "w", "`", "a", "m", "c", "'", "i"} {
+		v, _ = v.handleNvimInput(k)
+		if v.mode != "NORMAL" {
+			t.Fatalf("after %q: mode = %s, want NORMAL", k, v.mode)
+		}
+	}
+	if want := []string{"ma", "w", "`a", "mc", "'i"}; !reflect.DeepEqual(v.keyLog, want) {
+		t.Errorf("sent %q, want %q", v.keyLog, want)
+	}
+	if v.keystrokes != 9 {
+		t.Errorf("keystrokes = %d, want 9", v.keystrokes)
+	}
+}
+
+func TestCtrlVBlockMode(t *testing.T) {
+	if got := translateKey(tea.KeyMsg{Type: tea.KeyCtrlV}); got != "<C-v>" {
+		t.Errorf("translateKey(ctrl+v) = %q, want <C-v>", got)
+	}
+	for _, seq := range []string{"\x1b[118;5u", "\x1b[86;6u"} {
+		if got := translateCSIu([]byte(seq)); got != "<C-v>" {
+			t.Errorf("translateCSIu(%q) = %q, want <C-v>", seq, got)
+		}
+	}
+
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+	v, _ = v.Update(initPuzzleMsg{})
+	v, _ = v.Update([]byte("\x1b[118;5u"))
+	if v.mode != "V-BLOCK" {
+		t.Fatalf("mode after CSI-u ctrl+v = %s, want V-BLOCK", v.mode)
+	}
+	for _, k := range []string{"2", "j", "$", "A"} {
+		v, _ = v.handleNvimInput(k)
+	}
+	if v.mode != "INSERT" {
+		t.Errorf("mode after block A = %s, want INSERT", v.mode)
+	}
+	for _, k := range []string{";", "<Esc>"} {
+		v, _ = v.handleNvimInput(k)
+	}
+	if v.mode != "NORMAL" {
+		t.Errorf("mode after <Esc> = %s, want NORMAL", v.mode)
+	}
+	if want := []string{"<C-v>", "2", "j", "$", "A", ";", "<Esc>"}; !reflect.DeepEqual(v.keyLog, want) {
+		t.Errorf("sent %q, want %q", v.keyLog, want)
+	}
+}
+
+func TestMacroPlayback(t *testing.T) {
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+	for _, k := range []string{"@", "a", "@", "@", "5", "@", "a", "3", "@", "@"} {
+		v, _ = v.handleNvimInput(k)
+	}
+	if want := []string{"@a", "@@", "5@a", "3@@"}; !reflect.DeepEqual(v.keyLog, want) {
+		t.Errorf("sent %q, want %q", v.keyLog, want)
+	}
+	if v.keystrokes != 10 {
+		t.Errorf("keystrokes = %d, want 10", v.keystrokes)
+	}
+	if v.mode != "NORMAL" || v.pendingKeys != "" || v.pendingCount != "" {
+		t.Errorf("left mode=%s pending=%q count=%q", v.mode, v.pendingKeys, v.pendingCount)
+	}
+
+	// A macro stored in register c must not be mistaken for a change.
+	v, _ = v.handleNvimInput("@")
+	v, _ = v.handleNvimInput("c")
+	if v.mode != "NORMAL" {
+		t.Errorf("mode after @c = %s, want NORMAL", v.mode)
+	}
+}
+
+func TestWindowCommandPrefix(t *testing.T) {
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+	v, _ = v.handleNvimInput("<C-w>")
+	if v.pendingKeys != "<C-w>" || len(v.keyLog) != 0 {
+		t.Fatalf("<C-w> not buffered: pending=%q sent=%q", v.pendingKeys, v.keyLog)
+	}
+	for _, k := range []string{"w", "2", "<C-w>", "j", "<C-w>", "<Esc>"} {
+		v, _ = v.handleNvimInput(k)
+	}
+	if want := []string{"<C-w>w", "2<C-w>j"}; !reflect.DeepEqual(v.keyLog, want) {
+		t.Errorf("sent %q, want %q", v.keyLog, want)
+	}
+	if v.mode != "NORMAL" {
+		t.Errorf("mode = %s, want NORMAL", v.mode)
+	}
+
+	// In insert mode <C-w> deletes a word and is sent straight away.
+	v.mode = "INSERT"
+	v, _ = v.handleNvimInput("<C-w>")
+	if v.pendingKeys != "" || v.keyLog[len(v.keyLog)-1] != "<C-w>" {
+		t.Errorf("insert-mode <C-w> buffered: pending=%q", v.pendingKeys)
+	}
+}
+
+func TestEscCancelIsFree(t *testing.T) {
+	tests := []struct {
+		name       string
+		keys       []string
+		keystrokes int
+		sent       []string
+	}{
+		{"count", []string{"3", "<Esc>"}, 0, nil},
+		{"find", []string{"f", "<Esc>"}, 0, nil},
+		{"counted replace", []string{"2", "r", "<Esc>"}, 0, nil},
+		{"register", []string{`"`, "a", "<Esc>"}, 0, nil},
+		{"normal mode esc", []string{"<Esc>"}, 1, []string{"<Esc>"}},
+		{"leaving insert", []string{"i", "x", "<Esc>"}, 3, []string{"i", "x", "<Esc>"}},
+		{"after a complete command", []string{"r", "x", "<Esc>"}, 3, []string{"rx", "<Esc>"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+			for _, k := range tt.keys {
+				v, _ = v.handleNvimInput(k)
+			}
+			if v.keystrokes != tt.keystrokes {
+				t.Errorf("keystrokes = %d, want %d", v.keystrokes, tt.keystrokes)
+			}
+			if !reflect.DeepEqual(v.keyLog, tt.sent) {
+				t.Errorf("sent %q, want %q", v.keyLog, tt.sent)
+			}
+			if v.mode != "NORMAL" || v.showcmd() != "" {
+				t.Errorf("left mode=%s showcmd=%q", v.mode, v.showcmd())
+			}
+		})
+	}
+}
+
+func TestAbandonedOperatorIsFree(t *testing.T) {
+	tests := []struct {
+		name       string
+		keys       []string
+		keystrokes int
+		sent       []string
+	}{
+		{"operator", []string{"d", "<Esc>"}, 0, nil},
+		{"operator with count", []string{"d", "2", "<Esc>"}, 0, nil},
+		{"counted operator", []string{"3", "c", "<Esc>"}, 0, nil},
+		{"text object", []string{"d", "i", "<Esc>"}, 0, nil},
+		{"find target", []string{"c", "f", "<Esc>"}, 0, nil},
+		{"register and operator", []string{`"`, "a", "y", "<Esc>"}, 0, nil},
+		{"g operator", []string{"g", "U", "<Esc>"}, 0, nil},
+		{"then a real command", []string{"d", "<Esc>", "x"}, 1, []string{"x"}},
+		{"completed operator counts", []string{"d", "w", "d", "<Esc>"}, 2, []string{"dw"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+			for _, k := range tt.keys {
+				v, _ = v.handleNvimInput(k)
+			}
+			if v.keystrokes != tt.keystrokes {
+				t.Errorf("keystrokes = %d, want %d", v.keystrokes, tt.keystrokes)
+			}
+			if !reflect.DeepEqual(v.keyLog, tt.sent) {
+				t.Errorf("sent %q, want %q", v.keyLog, tt.sent)
+			}
+		})
+	}
+}
+
+// fakeNvim stands in for Neovim: it records the keys sent and serves the
+// buffer, cursor and mode the test sets.
+type fakeNvim struct {
+	sent  []string
+	loads []puzzle.Puzzle
+	lines []string
+	row   int
+	col   int
+	mode  string
+	reads int  // GetState calls
+	live  bool // LiveUpdates result
+}
+
+func (f *fakeNvim) Input(keys string) error {
+	f.sent = append(f.sent, keys)
+	return nil
+}
+
+func (f *fakeNvim) GetState() ([]string, int, int, string, error) {
+	f.reads++
+	mode := f.mode
+	if mode == "" {
+		mode = "n"
+	}
+	return f.lines, f.row, f.col, mode, nil
+}
+
+func (f *fakeNvim) GetRegister(string) (string, error) { return "", nil }
+
+func (f *fakeNvim) GetVisualSelection() (int, int, int, int, error) { return 0, 0, 0, 0, nil }
+
+func (f *fakeNvim) IsRecording() (string, error) { return "", nil }
+
+func (f *fakeNvim) LoadPuzzle(p puzzle.Puzzle) error {
+	f.loads = append(f.loads, p)
+	f.lines = strings.Split(p.Before.Text, "\n")
+	c := p.Before.StartCursor()
+	f.row, f.col, f.mode = c.Row, c.Col, "n"
+	return nil
+}
+
+func (f *fakeNvim) ResizeUI(int, int) {}
+
+func (f *fakeNvim) Ping(time.Duration) error { return nil }
+
+func (f *fakeNvim) Close() error { return nil }
+
+func (f *fakeNvim) LiveUpdates() bool { return f.live }
+
+func TestKeySequencesSent(t *testing.T) {
+	tests := []struct {
+		name       string
+		keys       []string
+		sent       []string
+		keystrokes int
+	}{
+		{"delete word", []string{"d", "w"}, []string{"dw"}, 2},
+		{"operator count", []string{"d", "2", "w"}, []string{"d2w"}, 3},
+		{"change inside quotes", []string{"c", "i", `"`}, []string{`ci"`}, 3},
+		{"counted line delete", []string{"3", "d", "d"}, []string{"3dd"}, 3},
+		{"find char", []string{"f", "X"}, []string{"fX"}, 2},
+		{"replace with space", []string{"r", " "}, []string{"r "}, 2},
+		{"yank into register", []string{`"`, "a", "y", "y"}, []string{`"ayy`}, 4},
+		{"count then motion", []string{"1", "2", "j"}, []string{"12j"}, 3},
+		{"motion then insert", []string{"w", "i", "x", "<Esc>"}, []string{"w", "i", "x", "<Esc>"}, 4},
+		{"less-than escaped", []string{"r", "<LT>"}, []string{"r<LT>"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeNvim{}
+			v := NewPuzzleView(testPuzzle(), fake, nil, nil)
+			for _, k := range tt.keys {
+				v, _ = v.handleNvimInput(k)
+			}
+			if !reflect.DeepEqual(fake.sent, tt.sent) {
+				t.Errorf("sent %q, want %q", fake.sent, tt.sent)
+			}
+			if v.keystrokes != tt.keystrokes {
+				t.Errorf("keystrokes = %d, want %d", v.keystrokes, tt.keystrokes)
+			}
+		})
+	}
+}
+
+func TestTranslateKey(t *testing.T) {
+	tests := []struct {
+		msg  tea.KeyMsg
+		want string
+	}{
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")}, "w"},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("<")}, "<LT>"},
+		{tea.KeyMsg{Type: tea.KeySpace}, " "},
+		{tea.KeyMsg{Type: tea.KeyEsc}, "<Esc>"},
+		{tea.KeyMsg{Type: tea.KeyEnter}, "<CR>"},
+		{tea.KeyMsg{Type: tea.KeyBackspace}, "<BS>"},
+		{tea.KeyMsg{Type: tea.KeyCtrlR}, "<C-r>"},
+		{tea.KeyMsg{Type: tea.KeyCtrlW}, "<C-w>"},
+	}
+	for _, tt := range tests {
+		if got := translateKey(tt.msg); got != tt.want {
+			t.Errorf("translateKey(%v) = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestFakeNvimSync(t *testing.T) {
+	fake := &fakeNvim{}
+	v := NewPuzzleView(testPuzzle(), fake, nil, nil)
+	v, _ = v.Update(initPuzzleMsg{})
+	if len(fake.loads) != 1 || !reflect.DeepEqual(v.lines, []string{"hello world"}) {
+		t.Fatalf("puzzle not loaded: loads=%d lines=%q", len(fake.loads), v.lines)
+	}
+
+	v, _ = v.handleNvimInput("i")
+	fake.mode = "i"
+	v, _ = v.Update(nvimSyncMsg{})
+	if v.mode != "INSERT" {
+		t.Errorf("mode = %s, want INSERT", v.mode)
+	}
+
+	v, _ = v.handleNvimInput("<Esc>")
+	fake.lines, fake.mode = []string{"world"}, "n"
+	v, _ = v.Update(nvimSyncMsg{})
+	v, _ = v.Update(nvimSyncMsg{})
+	if v.state != stateCleared {
+		t.Errorf("state = %v, want cleared once the fake reports the goal", v.state)
+	}
+	if want := []string{"i", "<Esc>"}; !reflect.DeepEqual(fake.sent, want) {
+		t.Errorf("sent %q, want %q", fake.sent, want)
+	}
+}
+
+func TestSyncsCoalesce(t *testing.T) {
+	fake := &fakeNvim{}
+	v := NewPuzzleView(testPuzzle(), fake, nil, nil)
+	v, _ = v.Update(initPuzzleMsg{})
+	fake.reads = 0
+
+	var cmds int
+	for _, k := range []string{"w", "x", "x", "i", "a", "b", "<Esc>"} {
+		var cmd tea.Cmd
+		v, cmd = v.handleNvimInput(k)
+		if cmd != nil {
+			cmds++
+		}
+	}
+	if cmds != 1 {
+		t.Errorf("%d syncs scheduled for a burst of keys, want 1", cmds)
+	}
+	v, _ = v.Update(nvimSyncMsg{})
+	if fake.reads != 1 {
+		t.Errorf("%d state reads per sync, want 1", fake.reads)
+	}
+	if _, cmd := v.handleNvimInput("j"); cmd == nil {
+		t.Error("no sync scheduled after the queued one ran")
+	}
+}
+
+func TestLiveUpdatesSkipPolling(t *testing.T) {
+	fake := &fakeNvim{live: true}
+	v := NewPuzzleView(testPuzzle(), fake, nil, nil)
+	v, _ = v.Update(initPuzzleMsg{})
+	for _, k := range []string{"w", "d", "w"} {
+		var cmd tea.Cmd
+		if v, cmd = v.handleNvimInput(k); cmd != nil {
+			t.Errorf("after %q: polling sync scheduled despite live updates", k)
+		}
+	}
+	if want := []string{"w", "dw"}; !reflect.DeepEqual(fake.sent, want) {
+		t.Errorf("sent %q, want %q", fake.sent, want)
+	}
+
+	// The goal still needs a second, confirming sync.
+	fake.lines = []string{"world"}
+	if _, cmd := v.Update(nvimSyncMsg{}); cmd == nil {
+		t.Error("no confirming sync scheduled once the goal was seen")
+	}
+}
+
+func TestGhostDiff(t *testing.T) {
+	g, d := tokenGhost, tokenGhostDiff
+	tests := []struct {
+		line, goal string
+		want       []tokenKind
+	}{
+		{"hello there", "hello world", []tokenKind{g, g, g, g, g, g, d, d, d, d, d}},
+		{"abXcd", "abcd", []tokenKind{g, g, d, g, g}},
+		{"abcd", "abXcd", []tokenKind{g, g, g, g}},
+		{"foo", "", []tokenKind{d, d, d}},
+	}
+	for _, tt := range tests {
+		if got := ghostKinds(tt.line, tt.goal); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ghostKinds(%q, %q) = %v, want %v", tt.line, tt.goal, got, tt.want)
+		}
+	}
+
+	p := testPuzzle()
+	p.Before.Text = "hello world\nsame"
+	p.After.Text = "world\nsame"
+	v := NewPuzzleView(p, nil, nil, nil)
+	v.lines = []string{"hello world", "same", "extra"}
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyF6})
+	if !v.ghost {
+		t.Fatal("F6 did not turn the ghost diff on")
+	}
+	goal := strings.Split(v.currentGoal(), "\n")
+	if kinds := v.lineKinds(v.lines[0], 0, goal); len(kinds) != 11 || kinds[0] != d {
+		t.Errorf("differing line kinds = %v", kinds)
+	}
+	if kinds := v.lineKinds(v.lines[1], 1, goal); kinds != nil {
+		t.Errorf("matching line colored: %v", kinds)
+	}
+	if kinds := v.lineKinds(v.lines[2], 2, goal); len(kinds) != 5 || kinds[0] != d {
+		t.Errorf("line past the goal not marked: %v", kinds)
+	}
+	if !strings.Contains(v.renderBuffer(40, 5), "hello world") {
+		t.Error("ghost diff dropped buffer text")
+	}
+
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyF6})
+	if v.ghost || v.lineKinds(v.lines[0], 0, goal) != nil {
+		t.Error("F6 did not turn the ghost diff off")
+	}
+}
+
+// bruteForceView is the fit search View used to do: the most editor lines,
+// then the most goal lines, that fit the height.
+func bruteForceView(v PuzzleView) string {
+	contentWidth := v.width - 4
+	innerWidth := contentWidth - 4
+	for editorLines := min(max(len(v.lines), 1), v.height); editorLines >= 1; editorLines-- {
+		for goalLines := countLines(v.currentGoal()); goalLines >= 1; goalLines-- {
+			view := v.renderView(contentWidth, innerWidth, goalLines, editorLines)
+			if lipgloss.Height(view) <= v.height {
+				return view
+			}
+		}
+	}
+	return v.renderView(contentWidth, innerWidth, 1, 1)
+}
+
+func largePuzzleView(n int) PuzzleView {
+	before := make([]string, n)
+	after := make([]string, n)
+	for i := range before {
+		before[i] = fmt.Sprintf("line %d: some code to refactor", i)
+		after[i] = fmt.Sprintf("line %d: refactored code", i)
+	}
+	p := testPuzzle()
+	p.Before.Text = strings.Join(before, "\n")
+	p.After.Text = strings.Join(after, "\n")
+	v := NewPuzzleView(p, nil, nil, nil)
+	v.lines = before
+	v.cursorRow = n / 2
+	v.width = 80
+	return v
+}
+
+func TestViewHeightMatchesFitSearch(t *testing.T) {
+	v := largePuzzleView(30)
+	wrapping := largePuzzleView(10)
+	wrapping.puzzle.After.Text = strings.Repeat("a long goal line that wraps in the box ", 4) + "\n" + wrapping.puzzle.After.Text
+	for _, view := range []PuzzleView{v, wrapping} {
+		for _, h := range []int{5, 12, 20, 24, 40, 80} {
+			view.height = h
+			if got, want := view.View(), bruteForceView(view); got != want {
+				t.Errorf("height %d: View differs from the fit search (%d vs %d rows)", h, lipgloss.Height(got), lipgloss.Height(want))
+			}
+		}
+	}
+}
+
+func BenchmarkViewLargeBuffer(b *testing.B) {
+	v := largePuzzleView(200)
+	v.height = 40
+	for range b.N {
+		_ = v.View()
+	}
+}
+
+// markCursor makes the cursor visible in uncolored test output as [x].
+func markCursor(t *testing.T) {
+	t.Helper()
+	saved := cursorStyle
+	cursorStyle = lipgloss.NewStyle().Transform(func(s string) string { return "[" + s + "]" })
+	t.Cleanup(func() { cursorStyle = saved })
+}
+
+func TestRenderWideCharacters(t *testing.T) {
+	markCursor(t)
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+	tests := []struct {
+		name  string
+		line  string
+		col   int
+		width int
+		want  string
+	}{
+		{"cjk", "ab漢字cd", 3, 20, "ab漢[字]cd"},
+		{"after cjk", "ab漢字cd", 4, 20, "ab漢字[c]d"},
+		{"combining", "cafe\u0301!", 3, 20, "caf[e\u0301]!"},
+		{"after combining", "cafe\u0301!", 5, 20, "cafe\u0301[!]"},
+		{"emoji", "👍ok", 1, 20, "👍[o]k"},
+		{"past the end", "漢字", 2, 20, "漢字[ ]"},
+		{"clipped cjk", "漢字漢字漢字漢字漢字", 5, 7, "~漢[字]~"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := v.renderLineWithCursor(tt.line, tt.col, tt.width, nil, nil)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if w := lipgloss.Width(strings.NewReplacer("[", "", "]", "").Replace(got)); w > tt.width {
+				t.Errorf("%q is %d cells wide, more than %d", got, w, tt.width)
+			}
+		})
+	}
+}
+
+func TestRenderBufferCursorByteColumn(t *testing.T) {
+	markCursor(t)
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+	v.lines = []string{"日本x"}
+	v.cursorCol = 6 // Neovim's byte offset of x
+	if got := v.renderBuffer(20, 1); got != "日本[x]" {
+		t.Errorf("renderBuffer = %q, want 日本[x]", got)
+	}
+}
+
+func TestTruncateLineWide(t *testing.T) {
+	tests := []struct {
+		line  string
+		width int
+		want  string
+	}{
+		{"abcdef", 4, "abc~"},
+		{"漢字漢字", 8, "漢字漢字"},
+		{"漢字漢字", 5, "漢字~"},
+		{"漢字漢字", 4, "漢 ~"},
+		{"cafe\u0301 au lait", 5, "cafe\u0301~"},
+	}
+	for _, tt := range tests {
+		got := truncateLine(tt.line, tt.width)
+		if got != tt.want {
+			t.Errorf("truncateLine(%q, %d) = %q, want %q", tt.line, tt.width, got, tt.want)
+		}
+		if w := lipgloss.Width(got); w > tt.width {
+			t.Errorf("truncateLine(%q, %d) is %d cells wide", tt.line, tt.width, w)
+		}
+	}
+}
+
+func TestRenderTabs(t *testing.T) {
+	markCursor(t)
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+	line := "\tfoo\tbar"
+	tests := []struct {
+		name  string
+		col   int
+		width int
+		want  string
+	}{
+		{"after the second tab", 5, 40, "        foo     [b]ar"},
+		{"on a tab", 4, 40, "        foo[     ]bar"},
+		{"on the first tab", 0, 40, "[        ]foo     bar"},
+		{"clipped", 6, 10, "~o     b[a]r"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := v.renderLineWithCursor(line, tt.col, tt.width, nil, nil); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := truncateLine(line, 12); got != "        foo~" {
+		t.Errorf("truncateLine = %q", got)
+	}
+	if got := expandTabs("ab\tc"); got != "ab      c" {
+		t.Errorf("expandTabs = %q", got)
+	}
+}
+
+func TestClipMarkers(t *testing.T) {
+	markCursor(t)
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+	tests := []struct {
+		name  string
+		line  string
+		col   int
+		width int
+		want  string
+	}{
+		{"fits", "abcdef", 2, 6, "ab[c]def"},
+		{"clipped right only", "abcdefghij", 0, 6, "[a]bcde~"},
+		{"clipped left only", "abcdefghij", 9, 6, "~fghi[j]"},
+		{"clipped both sides", "abcdefghij", 5, 6, "~cde[f]~"},
+		{"cursor past the end", "abcdefghij", 10, 6, "~ghij[ ]"},
+		{"no cursor", "abcdefghij", -1, 6, "abcde~"},
+		{"wide glyph at the edge", "漢字漢字漢字", 0, 6, "[漢]字~"},
+		{"wide glyph clipped left", "漢字漢字漢字", 5, 6, "~漢[字]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := v.renderLineWithCursor(tt.line, tt.col, tt.width, nil, nil)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if w := lipgloss.Width(strings.NewReplacer("[", "", "]", "").Replace(got)); w > tt.width {
+				t.Errorf("%q is %d cells wide, more than %d", got, w, tt.width)
+			}
+		})
+	}
+}
+
+func TestPerfectRequiresNoHelp(t *testing.T) {
+	p := testPuzzle()
+	p.OptimalSolution = "xx"
+	tests := []struct {
+		name      string
+		keys      []tea.KeyMsg
+		freeHints bool
+		want      bool
+	}{
+		{"unaided", nil, false, true},
+		{"hint shown", []tea.KeyMsg{{Type: tea.KeyCtrlH}}, false, false},
+		{"solution shown", []tea.KeyMsg{{Type: tea.KeyCtrlO}, {Type: tea.KeyCtrlO}}, false, false},
+		{"key revealed", []tea.KeyMsg{{Type: tea.KeyF3}}, false, false},
+		{"solution shown with free hints", []tea.KeyMsg{{Type: tea.KeyCtrlO}, {Type: tea.KeyCtrlO}}, true, false},
+		{"hint shown with free hints", []tea.KeyMsg{{Type: tea.KeyCtrlH}}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prog, err := progress.Open(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := NewPuzzleView(p, nil, prog, nil)
+			v.settings.FreeHints = tt.freeHints
+			v, _ = v.Update(initPuzzleMsg{})
+			for _, k := range tt.keys {
+				v, _ = v.Update(k)
+			}
+			v, _ = v.handleNvimInput("x")
+			v, _ = v.handleNvimInput("x")
+			v.checkClear(p.After.Text)
+			v.checkClear(p.After.Text)
+			if v.state != stateCleared {
+				t.Fatal("puzzle not cleared")
+			}
+			if v.perfect != tt.want || prog.GetBest(p.ID).Perfect != tt.want {
+				t.Errorf("perfect = %v, saved %v; want %v", v.perfect, prog.GetBest(p.ID).Perfect, tt.want)
+			}
+		})
+	}
+}
+
+func TestMasteryHoldsUntilThreeStars(t *testing.T) {
+	clearWith := func(v PuzzleView, strokes int) PuzzleView {
+		for range strokes {
+			v, _ = v.handleNvimInput("x")
+		}
+		v.checkClear(v.puzzle.After.Text)
+		v.checkClear(v.puzzle.After.Text)
+		return v
+	}
+	advances := func(cmd tea.Cmd) bool {
+		if cmd == nil {
+			return false
+		}
+		msg, ok := cmd().(puzzleExitMsg)
+		return ok && msg.next
+	}
+
+	v := NewPuzzleView(testPuzzle(), nil, nil, nil)
+	v.settings.Mastery = true
+	v, _ = v.Update(initPuzzleMsg{})
+	if v = clearWith(v, 4); v.stars == puzzle.ThreeStar {
+		t.Fatal("four keys over a par of two should not earn three stars")
+	}
+	if !strings.Contains(v.View(), "Three stars needed") {
+		t.Error("clear screen doesn't ask for a retry")
+	}
+	attempt := v.attempt
+	v, cmd := v.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || v.state != statePlaying || v.attempt != attempt+1 {
+		t.Fatal("enter after a two-star clear did not retry")
+	}
+
+	v = clearWith(v, 4)
+	if _, cmd = v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")}); !advances(cmd) {
+		t.Error("s did not skip to the next puzzle")
+	}
+
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	v = clearWith(v, 2)
+	if _, cmd = v.Update(tea.KeyMsg{Type: tea.KeyEnter}); !advances(cmd) {
+		t.Error("enter after a three-star clear did not advance")
+	}
+
+	v.settings.Mastery = false
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	v = clearWith(v, 4)
+	if _, cmd = v.Update(tea.KeyMsg{Type: tea.KeyEnter}); !advances(cmd) {
+		t.Error("without mastery, enter did not advance")
+	}
+}
@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/vimgym/vimgym/internal/progress"
+)
+
+// colorblindStars draws stars as ★/☆ so ratings don't rely on color alone.
+var colorblindStars bool
+
+// applySettings updates the package-wide display preferences.
+func applySettings(s progress.Settings) {
+	colorblindStars = s.ColorblindStars
+	SetTheme(ThemeByName(s.Theme))
+}
+
+// settingOption is one entry on the settings screen: a toggle, a choice
+// cycled through when choice is set, or an action run when action is set.
+type settingOption struct {
+	label  string
+	desc   string
+	value  func(*progress.Settings) *bool
+	choice func(*progress.Settings) *string
+	action bool
+}
+
+// themeNames lists the theme names offered by the settings screen.
+func themeNames() []string {
+	names := make([]string, len(Themes))
+	for i, t := range Themes {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// cycle returns the entry after current in options, wrapping around.
+func cycle(options []string, current string) string {
+	for i, o := range options {
+		if o == current {
+			return options[(i+1)%len(options)]
+		}
+	}
+	return options[0]
+}
+
+var settingOptions = []settingOption{
+	{"Show timer", "Show the solving clock while playing", func(s *progress.Settings) *bool { return &s.ShowTimer }, nil, false},
+	{"Colorblind stars", "Draw stars as ★ and ☆ instead of gold and gray *", func(s *progress.Settings) *bool { return &s.ColorblindStars }, nil, false},
+	{"Auto-advance", "Go to the next puzzle shortly after a clear", func(s *progress.Settings) *bool { return &s.AutoAdvance }, nil, false},
+	{"Mastery", "Only advance after three stars; enter retries, s skips", func(s *progress.Settings) *bool { return &s.Mastery }, nil, false},
+	{"Hide solutions", "Disable solution reveal and copy (challenge mode)", func(s *progress.Settings) *bool { return &s.HideSolutions }, nil, false},
+	{"Free hints", "Viewing the hint or solution doesn't cost stars", func(s *progress.Settings) *bool { return &s.FreeHints }, nil, false},
+	{"Theme", "Color theme; light suits light terminal backgrounds", nil, func(s *progress.Settings) *string { return &s.Theme }, false},
+	{"Replay tutorial", "Save and play the first-run tutorial again", nil, nil, true},
+}
+
+// openSettingsMsg asks the app to show the settings screen.
+type openSettingsMsg struct{}
+
+// settingsDoneMsg leaves the settings screen; save reports whether the
+// edited settings should be kept, and tutorial whether to replay the
+// tutorial afterwards.
+type settingsDoneMsg struct {
+	settings progress.Settings
+	save     bool
+	tutorial bool
+}
+
+// SettingsView edits a draft copy of the settings.
+type SettingsView struct {
+	draft  progress.Settings
+	cursor int
+	width  int
+}
+
+// NewSettingsView creates a settings screen editing a copy of s.
+func NewSettingsView(s progress.Settings) SettingsView {
+	return SettingsView{draft: s}
+}
+
+func (v SettingsView) Update(msg tea.Msg) (SettingsView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "k":
+			if v.cursor > 0 {
+				v.cursor--
+			}
+		case "down", "j":
+			v.cursor = min(v.cursor+1, len(settingOptions)-1)
+		case " ":
+			opt := settingOptions[v.cursor]
+			if opt.action {
+				draft := v.draft
+				return v, func() tea.Msg { return settingsDoneMsg{settings: draft, save: true, tutorial: true} }
+			}
+			if opt.choice != nil {
+				c := opt.choice(&v.draft)
+				*c = cycle(themeNames(), ThemeByName(*c).Name)
+			} else {
+				b := opt.value(&v.draft)
+				*b = !*b
+			}
+		case "enter":
+			draft, tutorial := v.draft, settingOptions[v.cursor].action
+			return v, func() tea.Msg { return settingsDoneMsg{settings: draft, save: true, tutorial: tutorial} }
+		case "esc", "q":
+			return v, func() tea.Msg { return settingsDoneMsg{} }
+		}
+	}
+	return v, nil
+}
+
+func (v SettingsView) View() string {
+	width := v.width
+	if width <= 0 {
+		width = 80
+	}
+	lines := []string{titleStyle.MaxWidth(width).Render("VimGym - Settings")}
+	for i, opt := range settingOptions {
+		prefix, style := "  ", unselectedStyle
+		if i == v.cursor {
+			prefix, style = "> ", selectedStyle
+		}
+		box := "[ ]"
+		if opt.action {
+			box = "[>]"
+		} else if opt.choice != nil {
+			box = "<" + ThemeByName(*opt.choice(&v.draft)).Name + ">"
+		} else if *opt.value(&v.draft) {
+			box = "[x]"
+		}
+		lines = append(lines, fitWidth(fmt.Sprintf("%s%s %s  %s", prefix, box, style.Render(opt.label), mutedStyle.Render(opt.desc)), width))
+	}
+	lines = append(lines, "", helpStyle.MaxWidth(width).Render("  j/k: navigate  space: toggle/cycle/run  enter: save  esc: cancel"))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/vimgym/vimgym/internal/progress"
+)
+
+func TestSettingsViewToggleAndSave(t *testing.T) {
+	v := NewSettingsView(progress.DefaultSettings())
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	_, cmd := v.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	msg, ok := cmd().(settingsDoneMsg)
+	if !ok || !msg.save || !msg.settings.ColorblindStars || !msg.settings.ShowTimer {
+		t.Errorf("enter emitted %#v, want saved settings with colorblind stars", msg)
+	}
+
+	_, cmd = v.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if msg := cmd().(settingsDoneMsg); msg.save {
+		t.Error("esc should discard changes")
+	}
+}
+
+func TestThemeSetting(t *testing.T) {
+	defer SetTheme(DarkTheme)
+
+	v := NewSettingsView(progress.DefaultSettings())
+	for settingOptions[v.cursor].choice == nil {
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	}
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	if v.draft.Theme != "light" {
+		t.Fatalf("theme after one cycle = %q, want light", v.draft.Theme)
+	}
+
+	applySettings(v.draft)
+	if CurrentTheme().Name != "light" {
+		t.Errorf("CurrentTheme = %q, want light", CurrentTheme().Name)
+	}
+	if titleStyle.GetForeground() != LightTheme.Primary {
+		t.Error("titleStyle was not rebuilt from the light theme")
+	}
+
+	applySettings(progress.Settings{Theme: "no-such-theme"})
+	if CurrentTheme().Name != "dark" {
+		t.Errorf("unknown theme gave %q, want dark", CurrentTheme().Name)
+	}
+}
@@ -0,0 +1,140 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/vimgym/vimgym/internal/progress"
+	"github.com/vimgym/vimgym/internal/puzzle"
+)
+
+// statsBarWidth is the width of the per-track completion bars.
+const statsBarWidth = 20
+
+// openStatsMsg asks the app to show the statistics screen.
+type openStatsMsg struct{}
+
+// trendSolves is how many recent solves the trend sparkline covers.
+const trendSolves = 20
+
+// trendText draws the recent keys-over-par trend as a sparkline, or "" with
+// fewer than two recorded solves.
+func trendText(prog *progress.Store) string {
+	if prog == nil {
+		return ""
+	}
+	recent := prog.RecentHistory(trendSolves)
+	if len(recent) < 2 {
+		return ""
+	}
+	values := make([]int, len(recent))
+	for i, a := range recent {
+		values[i] = a.OverPar()
+	}
+	return fmt.Sprintf("Trend: %s (keys over par, last %d)", sparkline(values), len(values))
+}
+
+var sparkRunes = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders values as a compact bar chart scaled between their min and max.
+func sparkline(values []int) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = min(lo, v)
+		hi = max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		idx := 0
+		if hi > lo {
+			idx = (v - lo) * (len(sparkRunes) - 1) / (hi - lo)
+		}
+		b.WriteRune(sparkRunes[idx])
+	}
+	return b.String()
+}
+
+// renderStats draws the statistics screen: overall totals, the recent trend,
+// per-track completion bars, and the hardest level still unsolved.
+func (a App) renderStats() string {
+	width := a.width
+	if width <= 0 {
+		width = 80
+	}
+	solved, total, percent := a.progress.OverallProgress(a.puzzles)
+
+	lines := []string{
+		titleStyle.MaxWidth(width).Render("VimGym - Statistics"),
+		fmt.Sprintf("Solved:        %d/%d (%d%%)", solved, total, percent),
+		fmt.Sprintf("Keystrokes:    %d  %s", a.progress.TotalKeystrokes(a.puzzles), mutedStyle.Render("(best solves)")),
+		fmt.Sprintf("Average stars: %.1f", a.progress.AverageStars(a.puzzles)),
+	}
+	if trend := trendText(a.progress); trend != "" {
+		lines = append(lines, mutedStyle.Render(trend))
+	}
+
+	lines = append(lines, "", labelStyle.Render("Tracks"))
+	for _, track := range puzzle.GetTracks(a.puzzles) {
+		done, count := a.progress.TrackProgress(track, a.puzzles)
+		lines = append(lines, fmt.Sprintf("  %s %3d/%-3d %s",
+			completionBar(done, count, statsBarWidth), done, count, trackName(track)))
+	}
+
+	lines = append(lines, "")
+	if level, ok := a.progress.HardestUnsolvedLevel(a.puzzles); ok {
+		lines = append(lines, fmt.Sprintf("Hardest unsolved: Lv %d: %s", level, levelDescriptions[level]))
+	} else {
+		lines = append(lines, labelStyle.Render("Every puzzle solved!"))
+	}
+
+	if a.statsStatus != "" {
+		lines = append(lines, labelStyle.Render(a.statsStatus))
+	}
+	lines = append(lines, helpStyle.MaxWidth(width).Render("  e: export JSON  c: export CSV  esc: back"))
+	for i, line := range lines {
+		lines[i] = fitWidth(line, width)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// updateStats exports progress on e (JSON) or c (CSV) and closes the
+// statistics screen on esc or q.
+func (a App) updateStats(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "e":
+			a.statsStatus = exportProgress(a.progress, progress.FormatJSON)
+		case "c":
+			a.statsStatus = exportProgress(a.progress, progress.FormatCSV)
+		case "esc", "q":
+			a.screen = screenTrack
+			return a, tea.ClearScreen
+		}
+	}
+	return a, nil
+}
+
+// exportProgress writes progress to a timestamped file in the home
+// directory and returns a status line naming it.
+func exportProgress(prog *progress.Store, format string) string {
+	name := fmt.Sprintf("~/vimgym-progress-%s.%s", time.Now().Format("20060102-150405"), format)
+	path := expandHome(name)
+	if err := prog.Export(path, format); err != nil {
+		return fmt.Sprintf("Export failed: %v", err)
+	}
+	return "Progress exported to " + path
+}
+
+// completionBar renders done out of total as a bar of the given width.
+func completionBar(done, total, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = done * width / total
+	}
+	return starStyle.Render(strings.Repeat("█", filled)) + mutedStyle.Render(strings.Repeat("░", width-filled))
+}
@@ -1,146 +1,356 @@
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"strings"
 
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is a set of colors the UI styles are built from.
+type Theme struct {
+	Name      string
+	Primary   lipgloss.Color
+	Secondary lipgloss.Color
+	Warning   lipgloss.Color
+	Danger    lipgloss.Color
+	Muted     lipgloss.Color
+	Text      lipgloss.Color
+	Bg        lipgloss.Color
+	Star      lipgloss.Color
+	// Keyword colors syntax keywords; OnAccent is text drawn on the
+	// colored mode badges and selections.
+	Keyword  lipgloss.Color
+	OnAccent lipgloss.Color
+}
+
+// DarkTheme is the default theme, for dark terminal backgrounds.
+var DarkTheme = Theme{
+	Name:      "dark",
+	Primary:   lipgloss.Color("#7C3AED"), // purple
+	Secondary: lipgloss.Color("#10B981"), // green
+	Warning:   lipgloss.Color("#F59E0B"), // yellow
+	Danger:    lipgloss.Color("#EF4444"), // red
+	Muted:     lipgloss.Color("#6B7280"), // gray
+	Text:      lipgloss.Color("#F9FAFB"), // white
+	Bg:        lipgloss.Color("#1F2937"), // dark bg
+	Star:      lipgloss.Color("#FBBF24"), // gold
+	Keyword:   lipgloss.Color("#C084FC"), // light purple
+	OnAccent:  lipgloss.Color("#000000"),
+}
+
+// LightTheme uses darker accents that stay readable on light backgrounds.
+var LightTheme = Theme{
+	Name:      "light",
+	Primary:   lipgloss.Color("#6D28D9"), // purple
+	Secondary: lipgloss.Color("#047857"), // green
+	Warning:   lipgloss.Color("#B45309"), // amber
+	Danger:    lipgloss.Color("#B91C1C"), // red
+	Muted:     lipgloss.Color("#6B7280"), // gray
+	Text:      lipgloss.Color("#111827"), // near black
+	Bg:        lipgloss.Color("#F9FAFB"), // light bg
+	Star:      lipgloss.Color("#D97706"), // dark gold
+	Keyword:   lipgloss.Color("#7E22CE"), // purple
+	OnAccent:  lipgloss.Color("#FFFFFF"),
+}
+
+// Themes lists the selectable themes in settings order.
+var Themes = []Theme{DarkTheme, LightTheme}
+
+// ThemeByName returns the named theme, or DarkTheme if there is none.
+func ThemeByName(name string) Theme {
+	for _, t := range Themes {
+		if t.Name == name {
+			return t
+		}
+	}
+	return DarkTheme
+}
+
+var currentTheme Theme
+
+// Styles, rebuilt from the active theme by SetTheme.
 var (
-	// Colors
-	colorPrimary   = lipgloss.Color("#7C3AED") // purple
-	colorSecondary = lipgloss.Color("#10B981") // green
-	colorWarning   = lipgloss.Color("#F59E0B") // yellow
-	colorDanger    = lipgloss.Color("#EF4444") // red
-	colorMuted     = lipgloss.Color("#6B7280") // gray
-	colorText      = lipgloss.Color("#F9FAFB") // white
-	colorBg        = lipgloss.Color("#1F2937") // dark bg
-	colorStar      = lipgloss.Color("#FBBF24") // gold
+	titleStyle         lipgloss.Style
+	goalBoxStyle       lipgloss.Style
+	editorBoxStyle     lipgloss.Style
+	labelStyle         lipgloss.Style
+	mutedStyle         lipgloss.Style
+	statusBarStyle     lipgloss.Style
+	modeNormalStyle    lipgloss.Style
+	modeInsertStyle    lipgloss.Style
+	modeVisualStyle    lipgloss.Style
+	starStyle          lipgloss.Style
+	noStarStyle        lipgloss.Style
+	syntaxKeywordStyle lipgloss.Style
+	syntaxStringStyle  lipgloss.Style
+	syntaxCommentStyle lipgloss.Style
+	syntaxNumberStyle  lipgloss.Style
+	perfectStyle       lipgloss.Style
+	masteredStyle      lipgloss.Style
+	recordingStyle     lipgloss.Style
+	difficultyStyle    lipgloss.Style
+	favoriteStyle      lipgloss.Style
+	trackHeaderStyle   lipgloss.Style
+	selectedStyle      lipgloss.Style
+	unselectedStyle    lipgloss.Style
+	lockedStyle        lipgloss.Style
+	helpStyle          lipgloss.Style
+	dangerStyle        lipgloss.Style
+	successStyle       lipgloss.Style
+	hintStyle          lipgloss.Style
+	solutionStyle      lipgloss.Style
+	explanationStyle   lipgloss.Style
+	cursorStyle        lipgloss.Style
+	selectionStyle     lipgloss.Style
+	ghostStyle         lipgloss.Style
+	ghostDiffStyle     lipgloss.Style
+	diffInsertStyle    lipgloss.Style
+	diffDeleteStyle    lipgloss.Style
+)
+
+func init() {
+	SetTheme(DarkTheme)
+}
+
+// CurrentTheme returns the active theme.
+func CurrentTheme() Theme {
+	return currentTheme
+}
+
+// SetTheme makes t the active theme and rebuilds every style from it.
+func SetTheme(t Theme) {
+	currentTheme = t
 
 	// Title
 	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(colorPrimary).
-			MarginBottom(1)
+		Bold(true).
+		Foreground(t.Primary).
+		MarginBottom(1)
 
 	// Box styles
 	goalBoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(colorSecondary).
-			Padding(0, 1).
-			MarginBottom(1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Secondary).
+		Padding(0, 1).
+		MarginBottom(1)
 
 	editorBoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(colorPrimary).
-			Padding(0, 1).
-			MarginBottom(1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Primary).
+		Padding(0, 1).
+		MarginBottom(1)
 
 	// Labels
 	labelStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(colorSecondary)
+		Bold(true).
+		Foreground(t.Secondary)
 
 	mutedStyle = lipgloss.NewStyle().
-			Foreground(colorMuted)
+		Foreground(t.Muted)
 
 	// Status bar
 	statusBarStyle = lipgloss.NewStyle().
-			Foreground(colorText).
-			MarginTop(1)
+		Foreground(t.Text).
+		MarginTop(1)
 
 	modeNormalStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#000000")).
-			Background(colorSecondary).
-			Padding(0, 1)
+		Bold(true).
+		Foreground(t.OnAccent).
+		Background(t.Secondary).
+		Padding(0, 1)
 
 	modeInsertStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#000000")).
-			Background(colorPrimary).
-			Padding(0, 1)
+		Bold(true).
+		Foreground(t.OnAccent).
+		Background(t.Primary).
+		Padding(0, 1)
 
 	modeVisualStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#000000")).
-			Background(colorWarning).
-			Padding(0, 1)
+		Bold(true).
+		Foreground(t.OnAccent).
+		Background(t.Warning).
+		Padding(0, 1)
 
 	// Stars
 	starStyle = lipgloss.NewStyle().
-			Foreground(colorStar)
+		Foreground(t.Star)
 
 	noStarStyle = lipgloss.NewStyle().
-			Foreground(colorMuted)
+		Foreground(t.Muted)
+
+	// Syntax colors for puzzles with a Language
+	syntaxKeywordStyle = lipgloss.NewStyle().
+		Foreground(t.Keyword)
+
+	syntaxStringStyle = lipgloss.NewStyle().
+		Foreground(t.Secondary)
+
+	syntaxCommentStyle = lipgloss.NewStyle().
+		Italic(true).
+		Foreground(t.Muted)
+
+	syntaxNumberStyle = lipgloss.NewStyle().
+		Foreground(t.Warning)
+
+	// Perfect badge, shown after three stars when the optimal solution was matched
+	perfectStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(t.Secondary)
+
+	// Mastered badge, shown once a puzzle or whole level has three stars
+	masteredStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(t.Star)
+
+	// Macro recording indicator in the status line
+	recordingStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(t.Danger)
+
+	// Filled dots of the puzzle difficulty indicator
+	difficultyStyle = lipgloss.NewStyle().
+		Foreground(t.Warning)
+
+	// Favorite marker in puzzle lists
+	favoriteStyle = lipgloss.NewStyle().
+		Foreground(t.Danger)
 
 	// Track header in level list
 	trackHeaderStyle = lipgloss.NewStyle().
-				Foreground(colorMuted).
-				Bold(true)
+		Foreground(t.Muted).
+		Bold(true)
 
 	// Menu items
 	selectedStyle = lipgloss.NewStyle().
-			Foreground(colorPrimary).
-			Bold(true)
+		Foreground(t.Primary).
+		Bold(true)
 
 	unselectedStyle = lipgloss.NewStyle().
-			Foreground(colorText)
+		Foreground(t.Text)
 
 	lockedStyle = lipgloss.NewStyle().
-			Foreground(colorMuted)
+		Foreground(t.Muted)
 
 	// Help text
 	helpStyle = lipgloss.NewStyle().
-			Foreground(colorMuted).
-			MarginTop(1)
+		Foreground(t.Muted).
+		MarginTop(1)
 
 	// Danger/warning text
 	dangerStyle = lipgloss.NewStyle().
-			Foreground(colorDanger).
-			Bold(true).
-			MarginTop(1)
+		Foreground(t.Danger).
+		Bold(true).
+		MarginTop(1)
 
 	// Success/clear message
 	successStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(colorSecondary).
-			Border(lipgloss.DoubleBorder()).
-			BorderForeground(colorSecondary).
-			Padding(1, 2).
-			Align(lipgloss.Center)
+		Bold(true).
+		Foreground(t.Secondary).
+		Border(lipgloss.DoubleBorder()).
+		BorderForeground(t.Secondary).
+		Padding(1, 2).
+		Align(lipgloss.Center)
 
 	// Hint
 	hintStyle = lipgloss.NewStyle().
-			Foreground(colorWarning).
-			Italic(true).
-			MarginTop(1)
+		Foreground(t.Warning).
+		Italic(true).
+		MarginTop(1)
 
 	// Solution
 	solutionStyle = lipgloss.NewStyle().
-			Foreground(colorPrimary).
-			Bold(true).
-			MarginTop(1)
+		Foreground(t.Primary).
+		Bold(true).
+		MarginTop(1)
 
 	// Solution explanation
 	explanationStyle = lipgloss.NewStyle().
-				Foreground(colorText).
-				Italic(true)
+		Foreground(t.Text).
+		Italic(true)
 
 	// Cursor character highlight
 	cursorStyle = lipgloss.NewStyle().
-			Reverse(true)
-)
+		Reverse(true)
 
-// FormatStars returns a star display string.
+	// Visual selection highlight
+	selectionStyle = lipgloss.NewStyle().
+		Foreground(t.OnAccent).
+		Background(t.Warning)
+
+	// Ghost diff: lines that differ from the goal, and the differing runes
+	ghostStyle = lipgloss.NewStyle().
+		Foreground(t.Muted)
+	ghostDiffStyle = lipgloss.NewStyle().
+		Foreground(t.Danger).
+		Underline(true)
+
+	// Before/after diff on the clear screen
+	diffInsertStyle = lipgloss.NewStyle().
+		Foreground(t.Secondary)
+	diffDeleteStyle = lipgloss.NewStyle().
+		Foreground(t.Danger).
+		Strikethrough(true)
+}
+
+// FormatStars returns a star display string, always three cells wide. With
+// colorblind stars on, earned and unearned stars differ by shape (★/☆) as
+// well as color.
 func FormatStars(stars int) string {
+	full, empty := "*", "*"
+	if colorblindStars {
+		full, empty = "★", "☆"
+	}
 	s := ""
 	for i := 0; i < 3; i++ {
 		if i < stars {
-			s += starStyle.Render("*")
+			s += starStyle.Render(full)
 		} else {
-			s += noStarStyle.Render("*")
+			s += noStarStyle.Render(empty)
 		}
 	}
 	return s
 }
 
+// PerfectBadge returns the perfect marker shown after the stars, or blank
+// padding of the same width so list columns line up.
+func PerfectBadge(perfect bool) string {
+	if !perfect {
+		return " "
+	}
+	return perfectStyle.Render("+")
+}
+
+// maxDifficulty is the number of dots in a difficulty indicator.
+const maxDifficulty = 5
+
+// FormatDifficulty returns a difficulty indicator of maxDifficulty dots,
+// filled up to d (clamped to 1-5), or blank padding when d is unset.
+func FormatDifficulty(d int) string {
+	if d <= 0 {
+		return strings.Repeat(" ", maxDifficulty)
+	}
+	d = min(d, maxDifficulty)
+	return difficultyStyle.Render(strings.Repeat("●", d)) + noStarStyle.Render(strings.Repeat("○", maxDifficulty-d))
+}
+
+// MasteredBadge returns the mastered marker, or blank padding of the same
+// width so list columns line up.
+func MasteredBadge(mastered bool) string {
+	if !mastered {
+		return "  "
+	}
+	return " " + masteredStyle.Render("◆")
+}
+
+// FavoriteBadge returns the favorite marker, or blank padding of the same
+// width so list columns line up.
+func FavoriteBadge(favorite bool) string {
+	if !favorite {
+		return "  "
+	}
+	return " " + favoriteStyle.Render("♥")
+}
+
 // ModeStyle returns the appropriate style for a vim mode.
 func ModeStyle(mode string) lipgloss.Style {
 	switch mode {
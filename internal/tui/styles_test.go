@@ -0,0 +1,26 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestFormatStarsColorblind(t *testing.T) {
+	defer func() { colorblindStars = false }()
+
+	for _, cb := range []bool{false, true} {
+		colorblindStars = cb
+		for stars := 0; stars <= 3; stars++ {
+			if w := lipgloss.Width(FormatStars(stars)); w != 3 {
+				t.Errorf("colorblind=%v stars=%d: width %d, want 3", cb, stars, w)
+			}
+		}
+	}
+
+	colorblindStars = true
+	if got := FormatStars(2); !strings.Contains(got, "★★") || !strings.Contains(got, "☆") {
+		t.Errorf("FormatStars(2) = %q, want two filled and one outline star", got)
+	}
+}
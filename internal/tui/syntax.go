@@ -0,0 +1,234 @@
+package tui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tokenKind classifies a buffer cell for syntax coloring.
+type tokenKind int
+
+const (
+	tokenPlain tokenKind = iota
+	tokenKeyword
+	tokenString
+	tokenComment
+	tokenNumber
+	// tokenGhost and tokenGhostDiff color a line that differs from the goal
+	// while the ghost diff is on: dimmed, with the differing runes marked.
+	tokenGhost
+	tokenGhostDiff
+)
+
+// languageSpec is the little a line-at-a-time highlighter needs to know.
+type languageSpec struct {
+	keywords     []string
+	lineComments []string
+	quotes       string
+	// caseInsensitive matches keywords regardless of case (e.g. SQL).
+	caseInsensitive bool
+}
+
+var (
+	goSpec = languageSpec{
+		keywords: []string{"break", "case", "chan", "const", "continue", "default", "defer", "else",
+			"fallthrough", "for", "func", "go", "goto", "if", "import", "interface", "map", "package",
+			"range", "return", "select", "struct", "switch", "type", "var", "nil", "true", "false"},
+		lineComments: []string{"//"},
+		quotes:       "\"'`",
+	}
+	pythonSpec = languageSpec{
+		keywords: []string{"and", "as", "assert", "async", "await", "break", "class", "continue", "def",
+			"del", "elif", "else", "except", "finally", "for", "from", "global", "if", "import", "in",
+			"is", "lambda", "nonlocal", "not", "or", "pass", "raise", "return", "try", "while", "with",
+			"yield", "None", "True", "False", "self"},
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	jsSpec = languageSpec{
+		keywords: []string{"async", "await", "break", "case", "catch", "class", "const", "continue",
+			"default", "delete", "do", "else", "export", "extends", "finally", "for", "from", "function",
+			"if", "import", "in", "instanceof", "interface", "let", "new", "of", "return", "switch",
+			"this", "throw", "try", "type", "typeof", "var", "void", "while", "yield", "null",
+			"undefined", "true", "false"},
+		lineComments: []string{"//"},
+		quotes:       "\"'`",
+	}
+	cSpec = languageSpec{
+		keywords: []string{"auto", "bool", "break", "case", "char", "class", "const", "continue",
+			"default", "do", "double", "else", "enum", "extern", "float", "for", "if", "include",
+			"int", "long", "namespace", "new", "private", "public", "return", "short", "signed",
+			"static", "struct", "switch", "template", "this", "typedef", "unsigned", "using", "void",
+			"while", "true", "false", "NULL", "nullptr"},
+		lineComments: []string{"//"},
+		quotes:       "\"'",
+	}
+	rustSpec = languageSpec{
+		keywords: []string{"as", "break", "const", "continue", "crate", "else", "enum", "fn", "for",
+			"if", "impl", "in", "let", "loop", "match", "mod", "mut", "pub", "ref", "return", "self",
+			"Self", "static", "struct", "trait", "type", "use", "where", "while", "true", "false"},
+		lineComments: []string{"//"},
+		quotes:       "\"",
+	}
+	shellSpec = languageSpec{
+		keywords: []string{"case", "do", "done", "elif", "else", "esac", "export", "fi", "for",
+			"function", "if", "in", "local", "return", "then", "while"},
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	luaSpec = languageSpec{
+		keywords: []string{"and", "break", "do", "else", "elseif", "end", "false", "for", "function",
+			"if", "in", "local", "nil", "not", "or", "repeat", "return", "then", "true", "until", "while"},
+		lineComments: []string{"--"},
+		quotes:       "\"'",
+	}
+	sqlSpec = languageSpec{
+		keywords: []string{"SELECT", "FROM", "WHERE", "AND", "OR", "NOT", "INSERT", "INTO", "VALUES",
+			"UPDATE", "SET", "DELETE", "CREATE", "TABLE", "JOIN", "LEFT", "INNER", "ON", "AS",
+			"ORDER", "GROUP", "BY", "LIMIT", "NULL"},
+		lineComments:    []string{"--"},
+		quotes:          "'\"",
+		caseInsensitive: true,
+	}
+	jsonSpec = languageSpec{
+		keywords: []string{"true", "false", "null"},
+		quotes:   "\"",
+	}
+)
+
+// languages maps Puzzle.Language values (lowercased) to their specs.
+var languages = map[string]languageSpec{
+	"go":         goSpec,
+	"python":     pythonSpec,
+	"py":         pythonSpec,
+	"javascript": jsSpec,
+	"js":         jsSpec,
+	"typescript": jsSpec,
+	"ts":         jsSpec,
+	"c":          cSpec,
+	"cpp":        cSpec,
+	"c++":        cSpec,
+	"java":       cSpec,
+	"rust":       rustSpec,
+	"rs":         rustSpec,
+	"sh":         shellSpec,
+	"bash":       shellSpec,
+	"shell":      shellSpec,
+	"lua":        luaSpec,
+	"sql":        sqlSpec,
+	"json":       jsonSpec,
+}
+
+// syntaxKinds returns the token kind of each rune in line, or nil when the
+// language is empty or unknown. Lines are scanned independently, so
+// constructs spanning lines (block comments, multi-line strings) are not
+// recognized.
+func syntaxKinds(line, lang string) []tokenKind {
+	spec, ok := languages[strings.ToLower(lang)]
+	if !ok {
+		return nil
+	}
+	runes := []rune(line)
+	kinds := make([]tokenKind, len(runes))
+	for i := 0; i < len(runes); {
+		switch r := runes[i]; {
+		case hasCommentAt(runes, i, spec.lineComments):
+			for ; i < len(runes); i++ {
+				kinds[i] = tokenComment
+			}
+		case strings.ContainsRune(spec.quotes, r):
+			start := i
+			for i++; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' {
+					i++
+				}
+			}
+			i = min(i+1, len(runes))
+			fill(kinds[start:i], tokenString)
+		case isIdentRune(r) && !unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && isIdentRune(runes[i]) {
+				i++
+			}
+			if isKeyword(spec, string(runes[start:i])) {
+				fill(kinds[start:i], tokenKeyword)
+			}
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (isIdentRune(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			fill(kinds[start:i], tokenNumber)
+		default:
+			i++
+		}
+	}
+	return kinds
+}
+
+// highlightLine renders line with syntax colors and no cursor.
+func highlightLine(line, lang string) string {
+	kinds := syntaxKinds(line, lang)
+	if kinds == nil {
+		return line
+	}
+	var b strings.Builder
+	for i, r := range []rune(line) {
+		if style, ok := syntaxStyle(kinds[i]); ok {
+			b.WriteString(style.Render(string(r)))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// syntaxStyle returns the style for a token kind; plain text has none.
+func syntaxStyle(k tokenKind) (lipgloss.Style, bool) {
+	switch k {
+	case tokenKeyword:
+		return syntaxKeywordStyle, true
+	case tokenString:
+		return syntaxStringStyle, true
+	case tokenComment:
+		return syntaxCommentStyle, true
+	case tokenNumber:
+		return syntaxNumberStyle, true
+	case tokenGhost:
+		return ghostStyle, true
+	case tokenGhostDiff:
+		return ghostDiffStyle, true
+	}
+	return lipgloss.Style{}, false
+}
+
+func hasCommentAt(runes []rune, i int, prefixes []string) bool {
+	for _, p := range prefixes {
+		prefix := []rune(p)
+		if len(runes)-i >= len(prefix) && string(runes[i:i+len(prefix)]) == p {
+			return true
+		}
+	}
+	return false
+}
+
+func isKeyword(spec languageSpec, word string) bool {
+	for _, k := range spec.keywords {
+		if k == word || (spec.caseInsensitive && strings.EqualFold(k, word)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func fill(kinds []tokenKind, k tokenKind) {
+	for i := range kinds {
+		kinds[i] = k
+	}
+}
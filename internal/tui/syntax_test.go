@@ -0,0 +1,30 @@
+package tui
+
+import "testing"
+
+func TestSyntaxKinds(t *testing.T) {
+	line := `x := "if" + 42 // if`
+	kinds := syntaxKinds(line, "go")
+	runes := []rune(line)
+	want := map[int]tokenKind{
+		0:              tokenPlain,   // x
+		5:              tokenString,  // opening quote
+		6:              tokenString,  // i inside the string, not a keyword
+		12:             tokenNumber,  // 4
+		15:             tokenComment, // /
+		len(runes) - 1: tokenComment,
+	}
+	for i, k := range want {
+		if kinds[i] != k {
+			t.Errorf("kind at %d (%q) = %v, want %v", i, runes[i], kinds[i], k)
+		}
+	}
+
+	kinds = syntaxKinds("func main", "Go")
+	if kinds[0] != tokenKeyword || kinds[5] != tokenPlain {
+		t.Errorf("keyword kinds = %v", kinds)
+	}
+	if syntaxKinds("func", "") != nil || syntaxKinds("func", "cobol") != nil {
+		t.Error("empty or unknown language should not be highlighted")
+	}
+}
@@ -2,7 +2,11 @@ package tui
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -18,6 +22,14 @@ var trackNames = map[int]string{
 	4: "Vim Golf (Challenge)",
 }
 
+// trackName returns the display name of a track.
+func trackName(track int) string {
+	if name := trackNames[track]; name != "" {
+		return name
+	}
+	return fmt.Sprintf("Track %d", track)
+}
+
 // Level descriptions
 var levelDescriptions = map[int]string{
 	1:  "Basic Movement",
@@ -57,6 +69,9 @@ type viewMode int
 const (
 	viewLevels viewMode = iota
 	viewPuzzles
+	viewReview
+	viewGallery
+	viewFavorites
 )
 
 // levelEntry represents a level in the flat list.
@@ -73,11 +88,39 @@ type TrackView struct {
 
 	allLevels  []levelEntry
 	puzzleList []puzzle.Puzzle
+	// levelsAll and listAll are the level and puzzle lists before filtering.
+	levelsAll []levelEntry
+	listAll   []puzzle.Puzzle
+
+	// filter narrows the current list by title or category; filterInput is
+	// set while it is being typed after "/".
+	filter      string
+	filterInput bool
+
+	// listLevel is the level whose puzzles are listed, restored as the
+	// cursor on the way back. byDifficulty orders the puzzle list from
+	// easiest to hardest instead of as loaded.
+	listLevel    int
+	byDifficulty bool
+
+	// levelDigits buffers a level number typed on the level list; levelSeq
+	// identifies the latest digit so stale jump timeouts are ignored.
+	levelDigits string
+	levelSeq    int
 
 	cursor       int
 	confirmReset bool
 	width        int
 	height       int
+
+	// exportPrompt is active while typing a path for the analytics export.
+	exportPrompt bool
+	exportPath   string
+	// status is a one-line message shown in the footer (e.g. export result).
+	status string
+	// puzzleSet names the external puzzle file in use, shown in the header;
+	// empty for the built-in puzzles.
+	puzzleSet string
 }
 
 // NewTrackView creates a new level selection view.
@@ -96,9 +139,20 @@ func NewTrackView(puzzles []puzzle.Puzzle, prog *progress.Store) TrackView {
 		progress:  prog,
 		mode:      viewLevels,
 		allLevels: allLevels,
+		levelsAll: allLevels,
 	}
 }
 
+// levelJumpDelay is how long after the last digit a typed level number is
+// jumped to without pressing enter.
+const levelJumpDelay = 800 * time.Millisecond
+
+// levelJumpMsg fires levelJumpDelay after a digit; seq matches levelSeq if
+// no digit was typed since.
+type levelJumpMsg struct {
+	seq int
+}
+
 // selectedPuzzle is a message sent when a puzzle is selected.
 type selectedPuzzle struct {
 	puzzle puzzle.Puzzle
@@ -110,12 +164,41 @@ func (v TrackView) Update(msg tea.Msg) (TrackView, tea.Cmd) {
 		v.width = msg.Width
 		v.height = msg.Height
 		return v, nil
+	case levelJumpMsg:
+		if msg.seq == v.levelSeq && v.levelDigits != "" {
+			v = v.jumpToLevel()
+		}
+		return v, nil
 	case tea.KeyMsg:
+		if v.exportPrompt {
+			return v.updateExportPrompt(msg), nil
+		}
+		if v.filterInput {
+			return v.updateFilterInput(msg), nil
+		}
+		v.status = ""
+		if v.mode == viewLevels && !v.confirmReset {
+			if key := msg.String(); len(key) == 1 && key[0] >= '0' && key[0] <= '9' {
+				v.levelDigits += key
+				v.levelSeq++
+				seq := v.levelSeq
+				return v, tea.Tick(levelJumpDelay, func(time.Time) tea.Msg { return levelJumpMsg{seq: seq} })
+			}
+			if v.levelDigits != "" {
+				if msg.String() == "enter" {
+					return v.jumpToLevel(), nil
+				}
+				// Any other key abandons the number.
+				v.levelDigits = ""
+			}
+		}
 		if v.confirmReset {
 			switch msg.String() {
 			case "y", "Y":
 				_ = v.progress.Reset()
+				set := v.puzzleSet
 				v = NewTrackView(v.puzzles, v.progress)
+				v.puzzleSet = set
 				return v, tea.ClearScreen
 			}
 			// Any other key cancels the reset prompt.
@@ -132,9 +215,71 @@ func (v TrackView) Update(msg tea.Msg) (TrackView, tea.Cmd) {
 		case "ctrl+r":
 			v.confirmReset = true
 			return v, nil
+		case "/":
+			if v.mode != viewGallery {
+				v.filterInput = true
+				return v, nil
+			}
+		case "A":
+			v.exportPrompt = true
+			v.exportPath = "~/vimgym-analytics.json"
+			return v, nil
+		case "s":
+			if v.mode == viewLevels {
+				return v, func() tea.Msg { return selectedPuzzle{puzzle: puzzle.Sandbox()} }
+			}
+		case "i":
+			if v.mode == viewLevels {
+				return v, func() tea.Msg { return openStatsMsg{} }
+			}
+		case "S":
+			if v.mode == viewLevels {
+				return v, func() tea.Msg { return openSettingsMsg{} }
+			}
+		case "n":
+			if v.mode == viewLevels {
+				if p, _, ok := v.progress.Recommend(v.puzzles); ok {
+					return v, func() tea.Msg { return selectedPuzzle{puzzle: p} }
+				}
+			}
+		case "r":
+			if v.mode == viewLevels {
+				if p, ok := v.progress.RandomUnsolved(v.puzzles); ok {
+					return v, func() tea.Msg { return selectedPuzzle{puzzle: p} }
+				}
+				v.status = "Every unlocked puzzle is solved!"
+				return v, nil
+			}
+		case "d":
+			if v.isPuzzleList() {
+				return v.toggleDifficultySort(), nil
+			}
+		case "f":
+			if v.isPuzzleList() {
+				return v.toggleFavorite(), nil
+			}
+		case "F":
+			if v.mode == viewLevels {
+				v = v.showList(viewFavorites, v.progress.FavoritePuzzles(v.puzzles))
+				return v, nil
+			}
+		case "g":
+			if v.mode == viewLevels {
+				v = v.showList(viewGallery, v.puzzles)
+				return v, nil
+			}
+		case "v":
+			if v.mode == viewLevels {
+				v = v.showList(viewReview, v.progress.ReviewQueue(v.puzzles))
+				return v, nil
+			}
 		case "enter", "l":
 			return v.selectItem()
 		case "esc", "h", "backspace":
+			if v.filter != "" && msg.String() == "esc" {
+				v.filter = ""
+				return v.applyFilter(), nil
+			}
 			return v.back(), nil
 		case "q":
 			if v.mode == viewLevels {
@@ -163,9 +308,19 @@ func (v TrackView) View() string {
 		headerLines := []string{
 			titleStyle.MaxWidth(width).Render("VimGym - Select Level"),
 		}
+		if v.puzzleSet != "" {
+			headerLines = append(headerLines, fitWidth(labelStyle.Render("Puzzles: ")+mutedStyle.Render(v.puzzleSet), width))
+		}
 		if progressText := overallProgressText(v.progress, v.puzzles); progressText != "" {
 			headerLines = append(headerLines, mutedStyle.MaxWidth(width).Render(progressText))
 		}
+		if streak := streakText(v.progress); streak != "" {
+			headerLines = append(headerLines, mutedStyle.MaxWidth(width).Render(streak))
+		}
+		if p, reason, ok := v.progress.Recommend(v.puzzles); ok {
+			rec := labelStyle.Render("Next: ") + selectedStyle.Render(p.Title) + mutedStyle.Render(" ("+reason+")  [n] play")
+			headerLines = append(headerLines, fitWidth(rec, width))
+		}
 		header := strings.Join(headerLines, "\n")
 
 		lastTrack := 0
@@ -178,11 +333,7 @@ func (v TrackView) View() string {
 				if lastTrack != 0 {
 					lines = append(lines, "")
 				}
-				name := trackNames[entry.track]
-				if name == "" {
-					name = fmt.Sprintf("Track %d", entry.track)
-				}
-				lines = append(lines, trackHeaderStyle.Render(fmt.Sprintf("── Track %d: %s ──", entry.track, name)))
+				lines = append(lines, v.trackHeader(entry.track))
 				lastTrack = entry.track
 			}
 
@@ -203,19 +354,23 @@ func (v TrackView) View() string {
 			if !unlocked {
 				style = lockedStyle
 				lockIcon := " [locked]"
+				if prev, have, need, gated := v.progress.TrackGate(entry.level, v.puzzles); gated && have < need {
+					lockIcon = fmt.Sprintf(" [locked: %d/%d stars in %s]", have, need, trackName(prev))
+				}
 				lines = append(lines, fmt.Sprintf("%s%s%s", prefix, style.Render(fmt.Sprintf("Lv %d: %s", entry.level, desc)), style.Render(lockIcon)))
 			} else {
 				stars := v.progress.GetLevelStars(entry.level, v.puzzles)
-				starStr := FormatStars(int(stars))
+				starStr := FormatStars(int(stars)) + MasteredBadge(v.progress.IsLevelMastered(entry.level, v.puzzles))
 				lines = append(lines, fmt.Sprintf("%s%s  %s", prefix, style.Render(fmt.Sprintf("Lv %d: %s", entry.level, desc)), starStr))
 			}
 			itemIndex++
 		}
-		helpLine := "  j/k: navigate  enter: select  q: quit  Ctrl+R: reset progress"
-		footer := helpStyle.MaxWidth(width).Render(helpLine)
-		if v.confirmReset {
-			footer = footer + "\n" + dangerStyle.MaxWidth(width).Render("Reset all progress? [y]es / [n]o")
+		if len(lines) == 0 {
+			lines = append(lines, mutedStyle.Render("  No levels match the filter."))
 		}
+		helpLine := "  j/k: navigate  enter: select  0-9: go to level  /: filter  r: random  v: review  F: favorites  g: gallery  i: stats  s: sandbox  S: settings  A: export  ?: help  q: quit  Ctrl+R: reset progress"
+		footer := helpStyle.MaxWidth(width).Render(helpLine)
+		footer += v.footerExtras(width)
 		available := height - lipgloss.Height(header) - lipgloss.Height(footer) - 2
 		if available < 1 {
 			available = 1
@@ -233,11 +388,19 @@ func (v TrackView) View() string {
 		b.WriteString("\n\n")
 		b.WriteString(footer)
 
-	case viewPuzzles:
-		level := v.puzzleList[0].Level
-		desc := levelDescriptions[level]
+	case viewPuzzles, viewReview, viewFavorites:
+		title := "Review Queue"
+		if v.mode == viewFavorites {
+			title = "Favorites"
+		}
+		if v.mode == viewPuzzles {
+			title = fmt.Sprintf("Level %d: %s", v.listLevel, levelDescriptions[v.listLevel])
+		}
+		if v.byDifficulty {
+			title += " (by difficulty)"
+		}
 		headerLines := []string{
-			titleStyle.MaxWidth(width).Render(fmt.Sprintf("Level %d: %s", level, desc)),
+			titleStyle.MaxWidth(width).Render(title),
 		}
 		if progressText := overallProgressText(v.progress, v.puzzles); progressText != "" {
 			headerLines = append(headerLines, mutedStyle.MaxWidth(width).Render(progressText))
@@ -256,7 +419,7 @@ func (v TrackView) View() string {
 			}
 
 			result := v.progress.GetBest(p.ID)
-			starStr := FormatStars(int(result.Stars))
+			starStr := FormatStars(int(result.Stars)) + PerfectBadge(result.Perfect) + MasteredBadge(v.progress.IsMastered(p.ID)) + FavoriteBadge(v.progress.IsFavorite(p.ID))
 			keystrokeInfo := ""
 			if result.Keystrokes > 0 {
 				keystrokeInfo = mutedStyle.Render(fmt.Sprintf(" (%d keys, par %d)", result.Keystrokes, p.Par))
@@ -264,13 +427,22 @@ func (v TrackView) View() string {
 				keystrokeInfo = mutedStyle.Render(fmt.Sprintf(" (par %d)", p.Par))
 			}
 
-			lines = append(lines, fmt.Sprintf("%s%s  %s%s", prefix, style.Render(p.Title), starStr, keystrokeInfo))
+			lines = append(lines, fmt.Sprintf("%s%s %s  %s%s", prefix, FormatDifficulty(p.Difficulty), style.Render(p.Title), starStr, keystrokeInfo))
 		}
-		helpLine := "  j/k: navigate  enter: start  esc: back  Ctrl+R: reset progress"
-		footer := helpStyle.MaxWidth(width).Render(helpLine)
-		if v.confirmReset {
-			footer = footer + "\n" + dangerStyle.MaxWidth(width).Render("Reset all progress? [y]es / [n]o")
+		if len(lines) == 0 && v.filter != "" {
+			lines = append(lines, mutedStyle.Render("  No puzzles match the filter."))
+		} else if len(lines) == 0 && v.mode == viewFavorites {
+			lines = append(lines, mutedStyle.Render("  No favorites yet. Press f on a puzzle to add it."))
+		} else if len(lines) == 0 {
+			lines = append(lines, mutedStyle.Render("  No puzzles need review."))
+		}
+		sortHelp := "d: sort by difficulty"
+		if v.byDifficulty {
+			sortHelp = "d: original order"
 		}
+		helpLine := "  j/k: navigate  enter: start  /: filter  " + sortHelp + "  f: favorite  esc: back  Ctrl+R: reset progress"
+		footer := helpStyle.MaxWidth(width).Render(helpLine)
+		footer += v.footerExtras(width)
 		available := height - lipgloss.Height(header) - lipgloss.Height(footer) - 2
 		if available < 1 {
 			available = 1
@@ -287,16 +459,82 @@ func (v TrackView) View() string {
 		}
 		b.WriteString("\n\n")
 		b.WriteString(footer)
+
+	case viewGallery:
+		b.WriteString(v.renderGallery(width, height))
 	}
 
 	return b.String()
 }
 
+// updateExportPrompt edits the analytics export path and writes the file on enter.
+func (v TrackView) updateExportPrompt(msg tea.KeyMsg) TrackView {
+	switch msg.Type {
+	case tea.KeyEsc:
+		v.exportPrompt = false
+	case tea.KeyEnter:
+		v.exportPrompt = false
+		path := expandHome(v.exportPath)
+		if err := v.progress.ExportAnalytics(path); err != nil {
+			v.status = fmt.Sprintf("Export failed: %v", err)
+		} else {
+			v.status = "Analytics exported locally to " + path
+		}
+	case tea.KeyBackspace:
+		if r := []rune(v.exportPath); len(r) > 0 {
+			v.exportPath = string(r[:len(r)-1])
+		}
+	case tea.KeySpace:
+		v.exportPath += " "
+	case tea.KeyRunes:
+		v.exportPath += string(msg.Runes)
+	}
+	return v
+}
+
+// expandHome replaces a leading "~/" with the user's home directory.
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
+
+// footerExtras renders prompts and status messages shared by all modes.
+func (v TrackView) footerExtras(width int) string {
+	var extra []string
+	if v.confirmReset {
+		extra = append(extra, dangerStyle.MaxWidth(width).Render("Reset all progress? [y]es / [n]o"))
+	}
+	if v.exportPrompt {
+		extra = append(extra, labelStyle.MaxWidth(width).Render("Export analytics to (local file only): "+v.exportPath+"_"))
+	}
+	if v.levelDigits != "" {
+		extra = append(extra, labelStyle.MaxWidth(width).Render("Go to level: "+v.levelDigits+"_"))
+	}
+	if v.filterInput {
+		extra = append(extra, labelStyle.MaxWidth(width).Render("/"+v.filter+"_"))
+	} else if v.filter != "" {
+		extra = append(extra, mutedStyle.MaxWidth(width).Render(fmt.Sprintf("Filter: %q  (/ to edit, esc to clear)", v.filter)))
+	}
+	if v.status != "" {
+		extra = append(extra, mutedStyle.MaxWidth(width).Render(v.status))
+	}
+	if len(extra) == 0 {
+		return ""
+	}
+	return "\n" + strings.Join(extra, "\n")
+}
+
 func (v TrackView) maxCursor() int {
 	switch v.mode {
 	case viewLevels:
 		return max(0, len(v.allLevels)-1)
-	case viewPuzzles:
+	case viewPuzzles, viewReview, viewGallery, viewFavorites:
 		return max(0, len(v.puzzleList)-1)
 	}
 	return 0
@@ -310,39 +548,210 @@ func (v TrackView) selectItem() (TrackView, tea.Cmd) {
 			if !v.progress.IsLevelUnlocked(entry.level, v.puzzles) {
 				return v, nil
 			}
-			v.puzzleList = puzzle.GetPuzzlesForLevel(v.puzzles, entry.level)
-			v.mode = viewPuzzles
-			v.cursor = 0
+			v = v.openLevel(entry.level)
 		}
-	case viewPuzzles:
+	case viewPuzzles, viewReview, viewFavorites:
 		if v.cursor < len(v.puzzleList) {
 			p := v.puzzleList[v.cursor]
 			return v, func() tea.Msg { return selectedPuzzle{puzzle: p} }
 		}
+	case viewGallery:
+		if v.cursor < len(v.puzzleList) {
+			p := v.puzzleList[v.cursor]
+			if !v.progress.IsLevelUnlocked(p.Level, v.puzzles) {
+				v.status = fmt.Sprintf("Level %d is locked.", p.Level)
+				return v, nil
+			}
+			return v, func() tea.Msg { return selectedPuzzle{puzzle: p} }
+		}
 	}
 	return v, nil
 }
 
+// openLevel lists the puzzles of level; going back lands on it in the
+// level list.
+func (v TrackView) openLevel(level int) TrackView {
+	v = v.showList(viewPuzzles, puzzle.GetPuzzlesForLevel(v.puzzles, level))
+	v.listLevel = level
+	return v
+}
+
 func (v TrackView) back() TrackView {
 	switch v.mode {
 	case viewPuzzles:
+		v.filter = ""
+		v.allLevels = v.levelsAll
 		// Go back to levels, restore cursor to the level we came from
-		if len(v.puzzleList) > 0 {
-			targetLevel := v.puzzleList[0].Level
-			for i, entry := range v.allLevels {
-				if entry.level == targetLevel {
-					v.cursor = i
-					break
-				}
+		v.cursor = 0
+		for i, entry := range v.allLevels {
+			if entry.level == v.listLevel {
+				v.cursor = i
+				break
 			}
-		} else {
-			v.cursor = 0
 		}
 		v.mode = viewLevels
+	case viewReview, viewGallery, viewFavorites:
+		v.filter = ""
+		v.allLevels = v.levelsAll
+		v.cursor = 0
+		v.mode = viewLevels
 	}
 	return v
 }
 
+// trackHeader renders a track's heading with its star rating and how many
+// of its levels are solved.
+func (v TrackView) trackHeader(track int) string {
+	stars := v.progress.GetTrackStars(track, v.puzzles)
+	solved, total := v.progress.TrackLevelsSolved(track, v.puzzles)
+	return fmt.Sprintf("%s  %s %s",
+		trackHeaderStyle.Render(fmt.Sprintf("── Track %d: %s ──", track, trackName(track))),
+		FormatStars(int(stars)),
+		mutedStyle.Render(fmt.Sprintf("(%d/%d levels)", solved, total)))
+}
+
+// jumpToLevel moves the cursor to the typed level number if it is listed
+// and unlocked, then clears the number.
+func (v TrackView) jumpToLevel() TrackView {
+	level, _ := strconv.Atoi(v.levelDigits)
+	v.levelDigits = ""
+	for i, entry := range v.allLevels {
+		if entry.level != level {
+			continue
+		}
+		if !v.progress.IsLevelUnlocked(level, v.puzzles) {
+			v.status = fmt.Sprintf("Level %d is locked.", level)
+			return v
+		}
+		v.cursor = i
+		return v
+	}
+	return v
+}
+
+// showList switches to a puzzle list mode showing list, unfiltered.
+func (v TrackView) showList(mode viewMode, list []puzzle.Puzzle) TrackView {
+	v.mode = mode
+	v.listAll = list
+	v.filter = ""
+	return v.applyFilter()
+}
+
+// isPuzzleList reports whether the view is one of the puzzle lists.
+func (v TrackView) isPuzzleList() bool {
+	return v.mode == viewPuzzles || v.mode == viewReview || v.mode == viewFavorites
+}
+
+// toggleFavorite bookmarks the puzzle under the cursor, or removes the
+// bookmark. Removing one from the favorites view drops it from the list.
+func (v TrackView) toggleFavorite() TrackView {
+	if v.cursor >= len(v.puzzleList) {
+		return v
+	}
+	v.progress.ToggleFavorite(v.puzzleList[v.cursor].ID)
+	if err := v.progress.Save(); err != nil {
+		v.status = fmt.Sprintf("Saving favorites failed: %v", err)
+	}
+	if v.mode == viewFavorites {
+		cursor := v.cursor
+		v.listAll = v.progress.FavoritePuzzles(v.puzzles)
+		v = v.applyFilter()
+		v.cursor = min(cursor, v.maxCursor())
+	}
+	return v
+}
+
+// toggleDifficultySort switches the puzzle list between loaded order and
+// easiest first, keeping the cursor on the same puzzle.
+func (v TrackView) toggleDifficultySort() TrackView {
+	var current string
+	if v.cursor < len(v.puzzleList) {
+		current = v.puzzleList[v.cursor].ID
+	}
+	v.byDifficulty = !v.byDifficulty
+	v = v.applyFilter()
+	for i, p := range v.puzzleList {
+		if p.ID == current {
+			v.cursor = i
+			break
+		}
+	}
+	return v
+}
+
+// updateFilterInput edits the filter, narrowing the list as it is typed.
+// Enter keeps the filter; esc drops it.
+func (v TrackView) updateFilterInput(msg tea.KeyMsg) TrackView {
+	switch msg.Type {
+	case tea.KeyEsc:
+		v.filterInput = false
+		v.filter = ""
+	case tea.KeyEnter:
+		v.filterInput = false
+		return v
+	case tea.KeyBackspace:
+		if r := []rune(v.filter); len(r) > 0 {
+			v.filter = string(r[:len(r)-1])
+		}
+	case tea.KeySpace:
+		v.filter += " "
+	case tea.KeyRunes:
+		v.filter += string(msg.Runes)
+	default:
+		return v
+	}
+	return v.applyFilter()
+}
+
+// applyFilter narrows the current list to entries matching the filter and
+// moves the cursor to the first match.
+func (v TrackView) applyFilter() TrackView {
+	v.cursor = 0
+	if v.mode == viewLevels {
+		v.allLevels = v.allLevels[:0:0]
+		for _, entry := range v.levelsAll {
+			if v.levelMatches(entry.level) {
+				v.allLevels = append(v.allLevels, entry)
+			}
+		}
+		return v
+	}
+	v.puzzleList = nil
+	for _, p := range v.listAll {
+		if puzzleMatches(p, v.filter) {
+			v.puzzleList = append(v.puzzleList, p)
+		}
+	}
+	if v.byDifficulty && v.mode != viewGallery {
+		puzzle.SortByDifficulty(v.puzzleList)
+	}
+	return v
+}
+
+// levelMatches reports whether a level's description, or any of its
+// puzzles, matches the filter.
+func (v TrackView) levelMatches(level int) bool {
+	if containsFold(levelDescriptions[level], v.filter) {
+		return true
+	}
+	for _, p := range puzzle.GetPuzzlesForLevel(v.puzzles, level) {
+		if puzzleMatches(p, v.filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// puzzleMatches reports whether filter is a case-insensitive substring of
+// the puzzle's title or category.
+func puzzleMatches(p puzzle.Puzzle, filter string) bool {
+	return containsFold(p.Title, filter) || containsFold(p.Category, filter)
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -0,0 +1,259 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vimgym/vimgym/internal/progress"
+	"github.com/vimgym/vimgym/internal/puzzle"
+)
+
+func testTrackView(t *testing.T, all []puzzle.Puzzle) TrackView {
+	t.Helper()
+	prog, err := progress.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewTrackView(all, prog)
+}
+
+func typeKeys(v TrackView, keys ...string) TrackView {
+	for _, k := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "backspace":
+			msg = tea.KeyMsg{Type: tea.KeyBackspace}
+		}
+		v, _ = v.Update(msg)
+	}
+	return v
+}
+
+func TestTrackViewFilter(t *testing.T) {
+	all := []puzzle.Puzzle{
+		{ID: "a", Track: 1, Level: 1, Title: "Move Around", Category: "hjkl"},
+		{ID: "b", Track: 1, Level: 2, Title: "Word Hops", Category: "word-motion"},
+		{ID: "c", Track: 1, Level: 2, Title: "Back Words", Category: "word-motion"},
+	}
+	v := testTrackView(t, all)
+	v.progress.SetBest("a", puzzle.ThreeStar, 1, nil)
+
+	v = typeKeys(v, "/", "h", "o", "p")
+	if len(v.allLevels) != 1 || v.allLevels[0].level != 2 || v.cursor != 0 {
+		t.Fatalf("levels matching %q = %v, want level 2", v.filter, v.allLevels)
+	}
+	if !strings.Contains(v.View(), "/hop_") {
+		t.Error("filter being typed is not shown in the footer")
+	}
+
+	v = typeKeys(v, "x")
+	if len(v.allLevels) != 0 || !strings.Contains(v.View(), "No levels match") {
+		t.Error("a filter with no matches should show an empty list")
+	}
+	v = typeKeys(v, "esc")
+	if v.filter != "" || len(v.allLevels) != 2 {
+		t.Error("esc did not cancel the filter")
+	}
+
+	// Open level 2 and narrow its puzzles; enter keeps the filter.
+	v = typeKeys(v, "j", "enter", "/", "b", "a", "c", "k", "enter")
+	if v.filterInput || len(v.puzzleList) != 1 || v.puzzleList[0].ID != "c" {
+		t.Fatalf("puzzles matching %q = %v, want c", v.filter, v.puzzleList)
+	}
+	if !strings.Contains(v.View(), `Filter: "back"`) {
+		t.Error("confirmed filter is not shown in the footer")
+	}
+
+	v = typeKeys(v, "/", "zzz", "enter")
+	if len(v.puzzleList) != 0 || !strings.Contains(v.View(), "No puzzles match") {
+		t.Error("a puzzle filter with no matches should show an empty list")
+	}
+	v = typeKeys(v, "esc", "esc")
+	if v.mode != viewLevels || v.allLevels[v.cursor].level != 2 {
+		t.Error("esc should clear the filter, then go back to level 2")
+	}
+}
+
+func TestJumpToLevelNumber(t *testing.T) {
+	var all []puzzle.Puzzle
+	for l := 1; l <= 12; l++ {
+		all = append(all, puzzle.Puzzle{ID: fmt.Sprint(l), Track: 1, Level: l})
+	}
+	v := testTrackView(t, all)
+	for l := 1; l <= 10; l++ {
+		v.progress.SetBest(fmt.Sprint(l), puzzle.OneStar, 9, nil)
+	}
+
+	v = typeKeys(v, "1", "1")
+	if !strings.Contains(v.View(), "Go to level: 11_") {
+		t.Error("typed level number is not shown in the footer")
+	}
+	v = typeKeys(v, "enter")
+	if v.mode != viewLevels || v.allLevels[v.cursor].level != 11 || v.levelDigits != "" {
+		t.Fatalf("enter after 11: mode %v, cursor on level %d; want the cursor on level 11", v.mode, v.allLevels[v.cursor].level)
+	}
+
+	// Level 12 is locked and 99 doesn't exist: the cursor stays put.
+	v = typeKeys(v, "1", "2", "enter", "9", "9", "enter")
+	if v.allLevels[v.cursor].level != 11 {
+		t.Errorf("cursor moved to level %d, want it to stay on 11", v.allLevels[v.cursor].level)
+	}
+
+	// The number is also applied after a pause; a stale timeout is ignored.
+	v = typeKeys(v, "3")
+	v, _ = v.Update(levelJumpMsg{seq: v.levelSeq - 1})
+	if v.allLevels[v.cursor].level != 11 {
+		t.Error("a stale timeout jumped")
+	}
+	v, _ = v.Update(levelJumpMsg{seq: v.levelSeq})
+	if v.allLevels[v.cursor].level != 3 {
+		t.Errorf("timeout jumped to level %d, want 3", v.allLevels[v.cursor].level)
+	}
+
+	// With no digits pending, enter still opens the level.
+	v = typeKeys(v, "enter")
+	if v.mode != viewPuzzles {
+		t.Error("enter without a number did not open the level")
+	}
+}
+
+func TestTrackHeaderSummary(t *testing.T) {
+	all := []puzzle.Puzzle{
+		{ID: "a", Track: 1, Level: 1},
+		{ID: "b", Track: 1, Level: 2},
+	}
+	v := testTrackView(t, all)
+	if !strings.Contains(v.View(), "(0/2 levels)") {
+		t.Error("track header lacks the solved-level count")
+	}
+	v.progress.SetBest("a", puzzle.ThreeStar, 1, nil)
+	if !strings.Contains(v.View(), "(1/2 levels)") {
+		t.Error("track header did not reflect new progress")
+	}
+}
+
+func TestPuzzleListDifficultySort(t *testing.T) {
+	all := []puzzle.Puzzle{
+		{ID: "a", Track: 1, Level: 1, Title: "Hard", Difficulty: 3},
+		{ID: "b", Track: 1, Level: 1, Title: "Easy", Difficulty: 1},
+		{ID: "c", Track: 1, Level: 1, Title: "Medium", Difficulty: 2},
+		{ID: "d", Track: 1, Level: 2, Title: "Next", Difficulty: 1},
+	}
+	v := testTrackView(t, all)
+	v.progress.SetBest("a", puzzle.OneStar, 9, nil)
+	v.progress.SetBest("b", puzzle.OneStar, 9, nil)
+	v.progress.SetBest("c", puzzle.OneStar, 9, nil)
+	v = typeKeys(v, "j", "k", "enter")
+	if v.mode != viewPuzzles || !strings.Contains(v.View(), "●●●○○") {
+		t.Fatalf("difficulty not shown in the puzzle list:\n%s", v.View())
+	}
+
+	v = typeKeys(v, "j", "j", "d")
+	ids := func() string {
+		var s string
+		for _, p := range v.puzzleList {
+			s += p.ID
+		}
+		return s
+	}
+	if got := ids(); got != "bca" {
+		t.Errorf("sorted order = %s, want bca", got)
+	}
+	if v.puzzleList[v.cursor].ID != "c" {
+		t.Errorf("cursor moved off the selected puzzle to %s", v.puzzleList[v.cursor].ID)
+	}
+	v = typeKeys(v, "d")
+	if got := ids(); got != "abc" {
+		t.Errorf("original order = %s, want abc", got)
+	}
+
+	v = typeKeys(v, "d", "esc")
+	if v.mode != viewLevels || v.cursor != 0 {
+		t.Errorf("back from a sorted list: mode %v cursor %d, want the level list on level 1", v.mode, v.cursor)
+	}
+	v = typeKeys(v, "j", "enter", "esc")
+	if v.cursor != 1 {
+		t.Errorf("back from level 2 put the cursor on %d", v.cursor)
+	}
+}
+
+func TestFavoritesView(t *testing.T) {
+	all := []puzzle.Puzzle{
+		{ID: "a", Track: 1, Level: 1, Title: "First"},
+		{ID: "b", Track: 1, Level: 1, Title: "Second"},
+	}
+	v := testTrackView(t, all)
+	v = typeKeys(v, "enter", "j", "f")
+	if !v.progress.IsFavorite("b") || !strings.Contains(v.View(), "♥") {
+		t.Fatalf("f did not favorite the puzzle:\n%s", v.View())
+	}
+
+	v = typeKeys(v, "esc", "F")
+	if v.mode != viewFavorites || len(v.puzzleList) != 1 || v.puzzleList[0].ID != "b" {
+		t.Fatalf("favorites view lists %v", v.puzzleList)
+	}
+	v = typeKeys(v, "f")
+	if v.progress.IsFavorite("b") || len(v.puzzleList) != 0 {
+		t.Error("unfavoriting did not remove the puzzle from the favorites view")
+	}
+	if !strings.Contains(v.View(), "No favorites yet") {
+		t.Errorf("empty favorites view:\n%s", v.View())
+	}
+}
+
+func TestPuzzleListColumnsWithPerfect(t *testing.T) {
+	all := []puzzle.Puzzle{
+		{ID: "a", Track: 1, Level: 1, Title: "Same", Par: 2},
+		{ID: "b", Track: 1, Level: 1, Title: "Same", Par: 2},
+	}
+	v := testTrackView(t, all)
+	v.progress.SetBest("a", puzzle.ThreeStar, 2, nil)
+	v.progress.MarkPerfect("a")
+	v.progress.SetBest("b", puzzle.ThreeStar, 2, nil)
+	v = typeKeys(v, "enter")
+
+	var cols []int
+	for _, line := range strings.Split(v.View(), "\n") {
+		if i := strings.Index(line, "(2 keys"); i >= 0 {
+			cols = append(cols, lipgloss.Width(line[:i]))
+		}
+	}
+	if len(cols) != 2 || cols[0] != cols[1] {
+		t.Errorf("keystroke columns at %v, want two equal columns", cols)
+	}
+}
+
+func TestRandomUnsolvedOnlyFromLevels(t *testing.T) {
+	all := []puzzle.Puzzle{
+		{ID: "a", Track: 1, Level: 1, Title: "First"},
+		{ID: "b", Track: 1, Level: 1, Title: "Second"},
+	}
+	v := testTrackView(t, all)
+	v, cmd := v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if cmd == nil {
+		t.Fatal("r on the level list did not open a puzzle")
+	}
+	if msg, ok := cmd().(selectedPuzzle); !ok || msg.puzzle.Level != 1 {
+		t.Errorf("r opened %v, want an unsolved level 1 puzzle", msg)
+	}
+
+	v = typeKeys(v, "enter")
+	if _, cmd := v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")}); cmd != nil || v.mode != viewPuzzles {
+		t.Error("r acted on the puzzle list")
+	}
+
+	v = typeKeys(v, "esc")
+	v.progress.SetBest("a", puzzle.OneStar, 9, nil)
+	v.progress.SetBest("b", puzzle.OneStar, 9, nil)
+	v = typeKeys(v, "r")
+	if !strings.Contains(v.View(), "Every unlocked puzzle is solved!") {
+		t.Errorf("no message with everything solved:\n%s", v.View())
+	}
+}
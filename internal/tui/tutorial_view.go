@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/vimgym/vimgym/internal/puzzle"
+)
+
+// tutorialNotes explain the puzzle screen before each tutorial puzzle, in
+// the order of puzzle.Tutorial.
+var tutorialNotes = []string{
+	"The goal is shown at the top and your buffer below it. Edit the buffer in Vim until it matches the goal; it clears on its own.",
+	"Every key counts. Solving in par keystrokes or fewer earns three stars. Ctrl+H shows a hint, and Ctrl+Q leaves a puzzle.",
+}
+
+// tutorialFinishNote describes the last entry, which ends the tutorial.
+const tutorialFinishNote = "Go to the track list. The same keys work there: j/k to move, enter to open, esc to go back, ? for help."
+
+// tutorialDoneMsg ends the tutorial, whether finished or skipped.
+type tutorialDoneMsg struct{}
+
+// TutorialView is the first-run tutorial: a short list of trivial puzzles
+// played with the normal puzzle screen, navigated like the track list.
+type TutorialView struct {
+	puzzles []puzzle.Puzzle
+	// cleared marks the puzzles solved during this run of the tutorial.
+	cleared []bool
+	cursor  int
+	width   int
+}
+
+// NewTutorialView creates the tutorial with the built-in tutorial puzzles.
+func NewTutorialView() TutorialView {
+	puzzles := puzzle.Tutorial()
+	return TutorialView{puzzles: puzzles, cleared: make([]bool, len(puzzles))}
+}
+
+// finish records the outcome of the tutorial puzzle with id, moving the
+// cursor on to the next entry when it was cleared.
+func (v TutorialView) finish(id string, cleared bool) TutorialView {
+	for i, p := range v.puzzles {
+		if p.ID != id || !cleared {
+			continue
+		}
+		v.cleared[i] = true
+		v.cursor = min(i+1, len(v.puzzles))
+	}
+	return v
+}
+
+func (v TutorialView) Update(msg tea.Msg) (TutorialView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "k":
+			if v.cursor > 0 {
+				v.cursor--
+			}
+		case "down", "j":
+			v.cursor = min(v.cursor+1, len(v.puzzles))
+		case "enter":
+			if v.cursor == len(v.puzzles) {
+				return v, func() tea.Msg { return tutorialDoneMsg{} }
+			}
+			p := v.puzzles[v.cursor]
+			return v, func() tea.Msg { return selectedPuzzle{puzzle: p} }
+		case "esc", "q":
+			return v, func() tea.Msg { return tutorialDoneMsg{} }
+		}
+	}
+	return v, nil
+}
+
+func (v TutorialView) View() string {
+	width := v.width
+	if width <= 0 {
+		width = 80
+	}
+	lines := []string{
+		titleStyle.MaxWidth(width).Render("Welcome to VimGym"),
+		"Each puzzle is a small edit to make in Neovim, in as few keystrokes as you can.",
+		"Menus are keyboard-driven: j and k move, enter opens. Try the two warm-ups below.",
+		"",
+	}
+	for i := 0; i <= len(v.puzzles); i++ {
+		prefix, style := "  ", unselectedStyle
+		if i == v.cursor {
+			prefix, style = "> ", selectedStyle
+		}
+		if i == len(v.puzzles) {
+			lines = append(lines, prefix+style.Render("Start training"))
+			continue
+		}
+		box := "[ ]"
+		if v.cleared[i] {
+			box = "[x]"
+		}
+		lines = append(lines, fmt.Sprintf("%s%s %s", prefix, box, style.Render(fmt.Sprintf("%d. %s", i+1, v.puzzles[i].Title))))
+	}
+
+	note := tutorialFinishNote
+	if v.cursor < len(v.puzzles) && v.cursor < len(tutorialNotes) {
+		note = tutorialNotes[v.cursor]
+	}
+	lines = append(lines, "", mutedStyle.Width(width).Render(note), "",
+		helpStyle.MaxWidth(width).Render("  j/k: navigate  enter: select  esc: skip tutorial"))
+	for i, line := range lines {
+		lines[i] = fitWidth(line, width)
+	}
+	return strings.Join(lines, "\n")
+}